@@ -0,0 +1,5 @@
+module github.com/catherinevee/terraform-gcp/tests
+
+go 1.22
+
+require github.com/gruntwork-io/terratest v0.47.2
@@ -0,0 +1,52 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// computeAddress mirrors the fields of a reserved IP address used by the
+// assertions in this file.
+type computeAddress struct {
+	Name        string   `json:"name"`
+	Address     string   `json:"address"`
+	AddressType string   `json:"addressType"`
+	Status      string   `json:"status"`
+	Users       []string `json:"users"`
+}
+
+// checkReservedStaticIP returns an error unless the address is IN_USE when
+// expectAttached is set and RESERVED (held but unattached) otherwise.
+func checkReservedStaticIP(addr computeAddress, expectAttached bool) error {
+	want := "RESERVED"
+	if expectAttached {
+		want = "IN_USE"
+	}
+	if addr.Status != want {
+		return fmt.Errorf("status is %s, want %s", addr.Status, want)
+	}
+	return nil
+}
+
+// AssertReservedStaticIP fails the test unless the regional static address
+// is reserved and, as expectAttached says, attached to a resource (IN_USE)
+// or not (RESERVED).
+func AssertReservedStaticIP(t *testing.T, projectID, region, addressName string, expectAttached bool) {
+	t.Helper()
+
+	var addr computeAddress
+	if err := gcloudJSON(&addr, "compute", "addresses", "describe", addressName,
+		"--region", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe address %s: %v", addressName, err)
+	}
+
+	users := make([]string, 0, len(addr.Users))
+	for _, u := range addr.Users {
+		users = append(users, lastSegment(u))
+	}
+	t.Logf("Address %s (%s %s) status=%s users=[%s]", addressName, addr.AddressType, addr.Address, addr.Status, strings.Join(users, ", "))
+	if err := checkReservedStaticIP(addr, expectAttached); err != nil {
+		t.Errorf("Address %s: %v", addressName, err)
+	}
+}
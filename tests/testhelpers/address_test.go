@@ -0,0 +1,32 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckReservedStaticIP(t *testing.T) {
+	cases := []struct {
+		name           string
+		raw            string
+		expectAttached bool
+		wantErr        bool
+	}{
+		{"in use", `{"name":"lb-ip","status":"IN_USE","users":["https://www.googleapis.com/compute/v1/projects/p/regions/r/forwardingRules/web"]}`, true, false},
+		{"reserved", `{"name":"spare-ip","status":"RESERVED"}`, false, false},
+		{"reserved but expected attached", `{"status":"RESERVED"}`, true, true},
+		{"in use but expected free", `{"status":"IN_USE"}`, false, true},
+		{"still reserving", `{"status":"RESERVING"}`, true, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var addr computeAddress
+			if err := json.Unmarshal([]byte(tc.raw), &addr); err != nil {
+				t.Fatal(err)
+			}
+			if err := checkReservedStaticIP(addr, tc.expectAttached); (err != nil) != tc.wantErr {
+				t.Errorf("checkReservedStaticIP() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
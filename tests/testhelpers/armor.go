@@ -0,0 +1,84 @@
+package testhelpers
+
+import (
+	"fmt"
+	"testing"
+)
+
+// securityPolicy mirrors the fields of a Cloud Armor security policy used by
+// the assertions in this file.
+type securityPolicy struct {
+	Name  string               `json:"name"`
+	Rules []securityPolicyRule `json:"rules"`
+}
+
+type securityPolicyRule struct {
+	Priority         int    `json:"priority"`
+	Action           string `json:"action"`
+	RateLimitOptions *struct {
+		RateLimitThreshold struct {
+			Count       int `json:"count"`
+			IntervalSec int `json:"intervalSec"`
+		} `json:"rateLimitThreshold"`
+	} `json:"rateLimitOptions"`
+}
+
+func (r securityPolicyRule) isRateLimit() bool {
+	return r.Action == "rate_based_ban" || r.Action == "throttle"
+}
+
+func (r securityPolicyRule) String() string {
+	if r.RateLimitOptions == nil {
+		return fmt.Sprintf("rule %d (%s, no rate limit options)", r.Priority, r.Action)
+	}
+	limit := r.RateLimitOptions.RateLimitThreshold
+	return fmt.Sprintf("rule %d (%s, %d requests per %ds)", r.Priority, r.Action, limit.Count, limit.IntervalSec)
+}
+
+// rateLimitRules returns the policy's rate_based_ban and throttle rules.
+func rateLimitRules(policy securityPolicy) []securityPolicyRule {
+	var rules []securityPolicyRule
+	for _, r := range policy.Rules {
+		if r.isRateLimit() {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// checkRateLimitRule returns an error unless one of the rate limit rules
+// allows threshold requests per interval seconds.
+func checkRateLimitRule(rules []securityPolicyRule, threshold, interval int) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("no rate_based_ban or throttle rule")
+	}
+	for _, r := range rules {
+		if r.RateLimitOptions == nil {
+			continue
+		}
+		limit := r.RateLimitOptions.RateLimitThreshold
+		if limit.Count == threshold && limit.IntervalSec == interval {
+			return nil
+		}
+	}
+	return fmt.Errorf("no rate limit rule allows %d requests per %ds", threshold, interval)
+}
+
+// AssertRateLimitRule fails the test unless the Cloud Armor security policy
+// has a rate_based_ban or throttle rule with the expected threshold count
+// and interval in seconds.
+func AssertRateLimitRule(t *testing.T, projectID, policyName string, expectThreshold int, expectInterval int) {
+	t.Helper()
+
+	var policy securityPolicy
+	if err := gcloudJSON(&policy, "compute", "security-policies", "describe", policyName, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe security policy %s: %v", policyName, err)
+	}
+	rules := rateLimitRules(policy)
+	for _, r := range rules {
+		t.Logf("Security policy %s: %s", policyName, r)
+	}
+	if err := checkRateLimitRule(rules, expectThreshold, expectInterval); err != nil {
+		t.Errorf("Security policy %s: %v", policyName, err)
+	}
+}
@@ -0,0 +1,42 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckRateLimitRule(t *testing.T) {
+	const raw = `{"name":"edge","rules":[
+		{"priority":1000,"action":"deny(403)"},
+		{"priority":2000,"action":"throttle","rateLimitOptions":{"rateLimitThreshold":{"count":100,"intervalSec":60}}},
+		{"priority":2147483647,"action":"allow"}]}`
+	var policy securityPolicy
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		t.Fatal(err)
+	}
+	rules := rateLimitRules(policy)
+	if len(rules) != 1 || rules[0].Priority != 2000 {
+		t.Fatalf("rateLimitRules() = %v, want only rule 2000", rules)
+	}
+
+	cases := []struct {
+		name      string
+		rules     []securityPolicyRule
+		threshold int
+		interval  int
+		wantErr   bool
+	}{
+		{"matches", rules, 100, 60, false},
+		{"wrong threshold", rules, 50, 60, true},
+		{"wrong interval", rules, 100, 120, true},
+		{"no rate limit rule", nil, 100, 60, true},
+		{"rule without options", []securityPolicyRule{{Priority: 10, Action: "rate_based_ban"}}, 100, 60, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkRateLimitRule(tc.rules, tc.threshold, tc.interval); (err != nil) != tc.wantErr {
+				t.Errorf("checkRateLimitRule() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// bigQueryDataset mirrors the fields of `bq show` output for a dataset used
+// by the assertions in this file.
+type bigQueryDataset struct {
+	DefaultEncryptionConfiguration *struct {
+		KMSKeyName string `json:"kmsKeyName"`
+	} `json:"defaultEncryptionConfiguration"`
+	Access []datasetAccessEntry `json:"access"`
+}
+
+// datasetAccessEntry is one grant in a dataset's access list. Exactly one of
+// the grantee fields is set.
+type datasetAccessEntry struct {
+	Role         string `json:"role"`
+	SpecialGroup string `json:"specialGroup"`
+	UserByEmail  string `json:"userByEmail"`
+	GroupByEmail string `json:"groupByEmail"`
+	Domain       string `json:"domain"`
+	IAMMember    string `json:"iamMember"`
+}
+
+// member returns the grantee in IAM member form, e.g. "group:eng@example.com"
+// or "allAuthenticatedUsers". Authorized views and routines have none.
+func (e datasetAccessEntry) member() string {
+	switch {
+	case e.SpecialGroup != "":
+		return e.SpecialGroup
+	case e.UserByEmail != "" && strings.HasSuffix(e.UserByEmail, ".gserviceaccount.com"):
+		return "serviceAccount:" + e.UserByEmail
+	case e.UserByEmail != "":
+		return "user:" + e.UserByEmail
+	case e.GroupByEmail != "":
+		return "group:" + e.GroupByEmail
+	case e.Domain != "":
+		return "domain:" + e.Domain
+	}
+	return e.IAMMember
+}
+
+func showDataset(t *testing.T, projectID, dataset string) bigQueryDataset {
+	t.Helper()
+
+	var ds bigQueryDataset
+	if err := bqJSON(&ds, "show", projectID+":"+dataset); err != nil {
+		t.Fatalf("Failed to show BigQuery dataset %s: %v", dataset, err)
+	}
+	return ds
+}
+
+// datasetKMSKey returns the dataset's default CMEK, or "" for Google-managed
+// encryption.
+func datasetKMSKey(ds bigQueryDataset) string {
+	if ds.DefaultEncryptionConfiguration == nil {
+		return ""
+	}
+	return ds.DefaultEncryptionConfiguration.KMSKeyName
+}
+
+// checkDatasetCMEK returns an error unless the dataset defaults to a
+// customer-managed key from one of the allowed key rings.
+func checkDatasetCMEK(ds bigQueryDataset, allowedKeyRings []string) error {
+	key := datasetKMSKey(ds)
+	if key == "" {
+		return fmt.Errorf("no default customer-managed encryption key configured")
+	}
+	if !keyRingAllowed(key, allowedKeyRings) {
+		return fmt.Errorf("default key %s is not in an allowed key ring", key)
+	}
+	return nil
+}
+
+// AssertBigQueryDatasetCMEK fails the test if the dataset's default
+// encryption does not use a customer-managed key from AllowedKMSKeyRings.
+func AssertBigQueryDatasetCMEK(t *testing.T, projectID, dataset string) {
+	t.Helper()
+
+	ds := showDataset(t, projectID, dataset)
+	t.Logf("BigQuery dataset %s default KMS key: %q", dataset, datasetKMSKey(ds))
+	if err := checkDatasetCMEK(ds, AllowedKMSKeyRings); err != nil {
+		t.Errorf("BigQuery dataset %s: %v", dataset, err)
+	}
+}
+
+// disallowedDatasetGrants describes every access entry granting a role to
+// one of disallowedMembers.
+func disallowedDatasetGrants(ds bigQueryDataset, disallowedMembers []string) []string {
+	disallowed := make(map[string]bool, len(disallowedMembers))
+	for _, m := range disallowedMembers {
+		disallowed[m] = true
+	}
+
+	var grants []string
+	for _, e := range ds.Access {
+		if m := e.member(); m != "" && disallowed[m] {
+			grants = append(grants, fmt.Sprintf("%s granted %s", m, e.Role))
+		}
+	}
+	return grants
+}
+
+// AssertBigQueryAccess fails the test for every grant in the dataset's
+// access list to one of disallowedMembers, given in IAM member form such as
+// "allAuthenticatedUsers" or "domain:example.com".
+func AssertBigQueryAccess(t *testing.T, projectID, dataset string, disallowedMembers []string) {
+	t.Helper()
+
+	ds := showDataset(t, projectID, dataset)
+	for _, e := range ds.Access {
+		if m := e.member(); m != "" {
+			t.Logf("BigQuery dataset %s grants %s to %s", dataset, e.Role, m)
+		}
+	}
+	for _, g := range disallowedDatasetGrants(ds, disallowedMembers) {
+		t.Errorf("BigQuery dataset %s: disallowed grant: %s", dataset, g)
+	}
+}
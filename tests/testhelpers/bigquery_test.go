@@ -0,0 +1,63 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCheckDatasetCMEK(t *testing.T) {
+	const ring = "projects/p/locations/europe-west1/keyRings/data"
+	cases := []struct {
+		name    string
+		raw     string
+		allowed []string
+		wantErr bool
+	}{
+		{"cmek in allowed ring", `{"defaultEncryptionConfiguration":{"kmsKeyName":"` + ring + `/cryptoKeys/bq"}}`, []string{ring}, false},
+		{"cmek with no ring restriction", `{"defaultEncryptionConfiguration":{"kmsKeyName":"` + ring + `/cryptoKeys/bq"}}`, nil, false},
+		{"cmek in other ring", `{"defaultEncryptionConfiguration":{"kmsKeyName":"projects/p/locations/us/keyRings/other/cryptoKeys/bq"}}`, []string{ring}, true},
+		{"google managed", `{}`, nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ds bigQueryDataset
+			if err := json.Unmarshal([]byte(tc.raw), &ds); err != nil {
+				t.Fatal(err)
+			}
+			if err := checkDatasetCMEK(ds, tc.allowed); (err != nil) != tc.wantErr {
+				t.Errorf("checkDatasetCMEK() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDisallowedDatasetGrants(t *testing.T) {
+	const raw = `{"access":[
+		{"role":"OWNER","specialGroup":"projectOwners"},
+		{"role":"READER","specialGroup":"allAuthenticatedUsers"},
+		{"role":"WRITER","userByEmail":"etl@p.iam.gserviceaccount.com"},
+		{"role":"READER","userByEmail":"analyst@example.com"},
+		{"role":"READER","groupByEmail":"eng@example.com"},
+		{"role":"READER","domain":"example.com"},
+		{"role":"READER","iamMember":"allUsers"},
+		{"view":{"projectId":"p","datasetId":"reports","tableId":"daily"}}]}`
+	var ds bigQueryDataset
+	if err := json.Unmarshal([]byte(raw), &ds); err != nil {
+		t.Fatal(err)
+	}
+
+	got := disallowedDatasetGrants(ds, []string{"allUsers", "allAuthenticatedUsers", "domain:example.com", "serviceAccount:etl@p.iam.gserviceaccount.com"})
+	want := []string{
+		"allAuthenticatedUsers granted READER",
+		"serviceAccount:etl@p.iam.gserviceaccount.com granted WRITER",
+		"domain:example.com granted READER",
+		"allUsers granted READER",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("disallowedDatasetGrants() = %v, want %v", got, want)
+	}
+	if got := disallowedDatasetGrants(ds, []string{"user:other@example.com"}); len(got) != 0 {
+		t.Errorf("disallowedDatasetGrants() = %v, want none", got)
+	}
+}
@@ -0,0 +1,117 @@
+package testhelpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testCacheDirEnv opts a run into module hash caching when set to a directory.
+const testCacheDirEnv = "TEST_CACHE_DIR"
+
+// hashModule returns a SHA-256 over the names and contents of the module's
+// top-level .tf files, in a stable order.
+func hashModule(modulePath string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no .tf files found in %s", modulePath)
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(file), len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// moduleHashFile returns the cache entry path for a module. Entries are keyed
+// by the module's absolute path so modules with the same name don't collide.
+func moduleHashFile(modulePath, cacheDir string) (string, error) {
+	abs, err := filepath.Abs(modulePath)
+	if err != nil {
+		return "", err
+	}
+	key := sha256.Sum256([]byte(abs))
+	return filepath.Join(cacheDir, hex.EncodeToString(key[:8])+".sha256"), nil
+}
+
+// ShouldSkipModule reports whether the module's .tf files are unchanged since
+// the hash last recorded by RecordModuleHash. A missing cache entry is a miss.
+func ShouldSkipModule(modulePath, cacheDir string) (bool, error) {
+	current, err := hashModule(modulePath)
+	if err != nil {
+		return false, err
+	}
+	entry, err := moduleHashFile(modulePath, cacheDir)
+	if err != nil {
+		return false, err
+	}
+	recorded, err := os.ReadFile(entry)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(recorded)) == current, nil
+}
+
+// RecordModuleHash stores the module's current hash after a green run.
+func RecordModuleHash(modulePath, cacheDir string) error {
+	current, err := hashModule(modulePath)
+	if err != nil {
+		return err
+	}
+	entry, err := moduleHashFile(modulePath, cacheDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(entry, []byte(current+"\n"), 0o644)
+}
+
+// SkipIfModuleUnchanged skips the test when TEST_CACHE_DIR is set and the
+// module hasn't changed since its last passing run. Otherwise it arranges for
+// the module hash to be recorded if the test passes.
+func SkipIfModuleUnchanged(t *testing.T, modulePath string) {
+	t.Helper()
+
+	cacheDir := os.Getenv(testCacheDirEnv)
+	if cacheDir == "" {
+		return
+	}
+
+	skip, err := ShouldSkipModule(modulePath, cacheDir)
+	if err != nil {
+		t.Logf("Module cache check for %s failed, running anyway: %v", modulePath, err)
+	}
+	if skip {
+		t.Skipf("Module %s unchanged since last green run", modulePath)
+	}
+
+	t.Cleanup(func() {
+		if t.Failed() || t.Skipped() {
+			return
+		}
+		if err := RecordModuleHash(modulePath, cacheDir); err != nil {
+			t.Logf("Failed to record module hash for %s: %v", modulePath, err)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package testhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShouldSkipModule(t *testing.T) {
+	module := t.TempDir()
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	writeFile(t, filepath.Join(module, "main.tf"), `resource "null_resource" "a" {}`)
+	writeFile(t, filepath.Join(module, "README.md"), "docs")
+
+	skip, err := ShouldSkipModule(module, cacheDir)
+	if err != nil || skip {
+		t.Fatalf("cold cache: skip=%t err=%v, want miss", skip, err)
+	}
+
+	if err := RecordModuleHash(module, cacheDir); err != nil {
+		t.Fatal(err)
+	}
+	skip, err = ShouldSkipModule(module, cacheDir)
+	if err != nil || !skip {
+		t.Fatalf("recorded hash: skip=%t err=%v, want hit", skip, err)
+	}
+
+	writeFile(t, filepath.Join(module, "README.md"), "docs changed")
+	if skip, _ = ShouldSkipModule(module, cacheDir); !skip {
+		t.Error("non-.tf change invalidated the cache")
+	}
+
+	writeFile(t, filepath.Join(module, "variables.tf"), `variable "x" {}`)
+	if skip, _ = ShouldSkipModule(module, cacheDir); skip {
+		t.Error("added .tf file did not invalidate the cache")
+	}
+}
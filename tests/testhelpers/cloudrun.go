@@ -0,0 +1,125 @@
+package testhelpers
+
+import (
+	"fmt"
+	"testing"
+)
+
+const (
+	vpcConnectorAnnotation  = "run.googleapis.com/vpc-access-connector"
+	vpcEgressAnnotation     = "run.googleapis.com/vpc-access-egress"
+	cpuThrottlingAnnotation = "run.googleapis.com/cpu-throttling"
+
+	// defaultContainerConcurrency applies when a revision leaves
+	// containerConcurrency unset.
+	defaultContainerConcurrency = 80
+)
+
+// cloudRunService mirrors the fields of `gcloud run services describe` output
+// (Knative serving format) used by the assertions in this file. Cloud
+// Functions (2nd gen) are served as Cloud Run services and share it.
+type cloudRunService struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Template struct {
+			Metadata struct {
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+			Spec struct {
+				ContainerConcurrency int `json:"containerConcurrency"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+func describeCloudRunService(t *testing.T, projectID, region, service string) cloudRunService {
+	t.Helper()
+
+	var svc cloudRunService
+	if err := gcloudJSON(&svc, "run", "services", "describe", service, "--region", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe Cloud Run service %s: %v", service, err)
+	}
+	return svc
+}
+
+func (s cloudRunService) templateAnnotation(key string) string {
+	return s.Spec.Template.Metadata.Annotations[key]
+}
+
+// checkServerlessVPCConnector returns the reasons the service does not
+// egress through expectConnector (and, if requireAllTraffic, with all-traffic
+// egress). Connectors compare by short name.
+func checkServerlessVPCConnector(svc cloudRunService, expectConnector string, requireAllTraffic bool) []string {
+	connector := svc.templateAnnotation(vpcConnectorAnnotation)
+	if connector == "" {
+		return []string{"no VPC connector attached"}
+	}
+
+	var problems []string
+	if lastSegment(connector) != lastSegment(expectConnector) {
+		problems = append(problems, fmt.Sprintf("VPC connector is %s, want %s", connector, expectConnector))
+	}
+	if egress := svc.templateAnnotation(vpcEgressAnnotation); requireAllTraffic && egress != "all-traffic" {
+		problems = append(problems, fmt.Sprintf("VPC egress is %q, want all-traffic", egress))
+	}
+	return problems
+}
+
+// AssertServerlessVPCConnector fails the test unless the Cloud Run service
+// (or 2nd gen Cloud Function) routes egress through expectConnector. If
+// requireAllTraffic is set, egress must also be all-traffic, so no request
+// leaves outside the VPC.
+func AssertServerlessVPCConnector(t *testing.T, projectID, region, service, expectConnector string, requireAllTraffic bool) {
+	t.Helper()
+
+	svc := describeCloudRunService(t, projectID, region, service)
+	t.Logf("Cloud Run service %s connector=%q egress=%q", service,
+		svc.templateAnnotation(vpcConnectorAnnotation), svc.templateAnnotation(vpcEgressAnnotation))
+	for _, p := range checkServerlessVPCConnector(svc, expectConnector, requireAllTraffic) {
+		t.Errorf("Cloud Run service %s: %s", service, p)
+	}
+}
+
+// containerConcurrency returns the revision's maximum concurrent requests per
+// instance, applying the default when unset.
+func (s cloudRunService) containerConcurrency() int {
+	if c := s.Spec.Template.Spec.ContainerConcurrency; c > 0 {
+		return c
+	}
+	return defaultContainerConcurrency
+}
+
+// cpuAlwaysAllocated reports whether CPU stays allocated outside requests,
+// i.e. CPU throttling is explicitly disabled.
+func (s cloudRunService) cpuAlwaysAllocated() bool {
+	return s.templateAnnotation(cpuThrottlingAnnotation) == "false"
+}
+
+// checkCloudRunResources returns the differences between the service's
+// per-instance concurrency and CPU allocation mode and the expected ones.
+func checkCloudRunResources(svc cloudRunService, expectConcurrency int, cpuAlwaysAllocated bool) []string {
+	var problems []string
+	if actual := svc.containerConcurrency(); actual != expectConcurrency {
+		problems = append(problems, fmt.Sprintf("container concurrency is %d, want %d", actual, expectConcurrency))
+	}
+	if actual := svc.cpuAlwaysAllocated(); actual != cpuAlwaysAllocated {
+		problems = append(problems, fmt.Sprintf("CPU always allocated is %t, want %t", actual, cpuAlwaysAllocated))
+	}
+	return problems
+}
+
+// AssertCloudRunResources fails the test unless the Cloud Run service serves
+// expectConcurrency requests per instance and allocates CPU always
+// (cpuAlwaysAllocated) or only during requests.
+func AssertCloudRunResources(t *testing.T, projectID, region, service string, expectConcurrency int, cpuAlwaysAllocated bool) {
+	t.Helper()
+
+	svc := describeCloudRunService(t, projectID, region, service)
+	t.Logf("Cloud Run service %s concurrency=%d cpuAlwaysAllocated=%t", service,
+		svc.containerConcurrency(), svc.cpuAlwaysAllocated())
+	for _, p := range checkCloudRunResources(svc, expectConcurrency, cpuAlwaysAllocated) {
+		t.Errorf("Cloud Run service %s: %s", service, p)
+	}
+}
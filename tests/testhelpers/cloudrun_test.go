@@ -0,0 +1,60 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeCloudRunService(t *testing.T, raw string) cloudRunService {
+	t.Helper()
+	var svc cloudRunService
+	if err := json.Unmarshal([]byte(raw), &svc); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestCheckServerlessVPCConnector(t *testing.T) {
+	cases := []struct {
+		name              string
+		annotations       string
+		requireAllTraffic bool
+		wantProblems      int
+	}{
+		{"full name all traffic", `{"run.googleapis.com/vpc-access-connector":"projects/p/locations/europe-west1/connectors/egress","run.googleapis.com/vpc-access-egress":"all-traffic"}`, true, 0},
+		{"private ranges only", `{"run.googleapis.com/vpc-access-connector":"egress","run.googleapis.com/vpc-access-egress":"private-ranges-only"}`, true, 1},
+		{"private ranges allowed", `{"run.googleapis.com/vpc-access-connector":"egress","run.googleapis.com/vpc-access-egress":"private-ranges-only"}`, false, 0},
+		{"other connector", `{"run.googleapis.com/vpc-access-connector":"legacy","run.googleapis.com/vpc-access-egress":"all-traffic"}`, true, 1},
+		{"no connector", `{}`, true, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := decodeCloudRunService(t, `{"spec":{"template":{"metadata":{"annotations":`+tc.annotations+`}}}}`)
+			if got := checkServerlessVPCConnector(svc, "egress", tc.requireAllTraffic); len(got) != tc.wantProblems {
+				t.Errorf("checkServerlessVPCConnector() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckCloudRunResources(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		concurrency  int
+		cpuAlways    bool
+		wantProblems int
+	}{
+		{"matches", `{"spec":{"template":{"metadata":{"annotations":{"run.googleapis.com/cpu-throttling":"false"}},"spec":{"containerConcurrency":250}}}}`, 250, true, 0},
+		{"defaults", `{}`, 80, false, 0},
+		{"throttled", `{"spec":{"template":{"metadata":{"annotations":{"run.googleapis.com/cpu-throttling":"true"}},"spec":{"containerConcurrency":250}}}}`, 250, true, 1},
+		{"both differ", `{"spec":{"template":{"spec":{"containerConcurrency":1}}}}`, 80, true, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkCloudRunResources(decodeCloudRunService(t, tc.raw), tc.concurrency, tc.cpuAlways); len(got) != tc.wantProblems {
+				t.Errorf("checkCloudRunResources() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
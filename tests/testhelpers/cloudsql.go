@@ -0,0 +1,210 @@
+package testhelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// MinCloudSQLBackupRetentionDays is the minimum number of daily automated
+// backups AssertCloudSQLPITR accepts.
+var MinCloudSQLBackupRetentionDays = 7
+
+// sqlInstance mirrors the fields of a Cloud SQL instance used by the
+// assertions in this file.
+type sqlInstance struct {
+	Name            string   `json:"name"`
+	DatabaseVersion string   `json:"databaseVersion"`
+	Region          string   `json:"region"`
+	ReplicaNames    []string `json:"replicaNames"`
+	Settings        struct {
+		IPConfiguration struct {
+			RequireSSL bool   `json:"requireSsl"`
+			SSLMode    string `json:"sslMode"`
+		} `json:"ipConfiguration"`
+		BackupConfiguration struct {
+			Enabled                     bool `json:"enabled"`
+			BinaryLogEnabled            bool `json:"binaryLogEnabled"`
+			PointInTimeRecoveryEnabled  bool `json:"pointInTimeRecoveryEnabled"`
+			TransactionLogRetentionDays int  `json:"transactionLogRetentionDays"`
+			BackupRetentionSettings     struct {
+				RetainedBackups int    `json:"retainedBackups"`
+				RetentionUnit   string `json:"retentionUnit"`
+			} `json:"backupRetentionSettings"`
+		} `json:"backupConfiguration"`
+		MaintenanceWindow *struct {
+			Day  int `json:"day"`
+			Hour int `json:"hour"`
+		} `json:"maintenanceWindow"`
+	} `json:"settings"`
+}
+
+func describeSQLInstance(t *testing.T, projectID, instance string) sqlInstance {
+	t.Helper()
+
+	var inst sqlInstance
+	if err := gcloudJSON(&inst, "sql", "instances", "describe", instance, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe Cloud SQL instance %s: %v", instance, err)
+	}
+	return inst
+}
+
+// pitrEnabled reports whether point-in-time recovery is on. MySQL implements
+// it with binary logging; PostgreSQL and SQL Server have an explicit flag.
+func (i sqlInstance) pitrEnabled() bool {
+	backup := i.Settings.BackupConfiguration
+	if strings.HasPrefix(i.DatabaseVersion, "MYSQL") {
+		return backup.BinaryLogEnabled
+	}
+	return backup.PointInTimeRecoveryEnabled
+}
+
+// checkCloudSQLPITR returns the backup policy violations for the instance.
+func checkCloudSQLPITR(inst sqlInstance, requireBinaryLog bool, minRetentionDays int) []string {
+	backup := inst.Settings.BackupConfiguration
+
+	var problems []string
+	if !backup.Enabled {
+		problems = append(problems, "automated backups are disabled")
+	}
+	if requireBinaryLog && !inst.pitrEnabled() {
+		problems = append(problems, "point-in-time recovery (binary logging) is disabled")
+	}
+	if retained := backup.BackupRetentionSettings.RetainedBackups; retained < minRetentionDays {
+		problems = append(problems, fmt.Sprintf("retains %d daily backups, want at least %d", retained, minRetentionDays))
+	}
+	return problems
+}
+
+// AssertCloudSQLPITR fails the test if automated backups are off, if
+// point-in-time recovery is off while requireBinaryLog is set, or if fewer
+// than MinCloudSQLBackupRetentionDays daily backups are retained.
+func AssertCloudSQLPITR(t *testing.T, projectID, instance string, requireBinaryLog bool) {
+	t.Helper()
+
+	inst := describeSQLInstance(t, projectID, instance)
+	backup := inst.Settings.BackupConfiguration
+	t.Logf("Cloud SQL %s (%s): backups=%t pitr=%t retained_backups=%d log_retention_days=%d",
+		instance, inst.DatabaseVersion, backup.Enabled, inst.pitrEnabled(),
+		backup.BackupRetentionSettings.RetainedBackups, backup.TransactionLogRetentionDays)
+
+	for _, p := range checkCloudSQLPITR(inst, requireBinaryLog, MinCloudSQLBackupRetentionDays) {
+		t.Errorf("Cloud SQL instance %s: %s", instance, p)
+	}
+}
+
+// checkCloudSQLRequireSSL returns an error if the instance accepts
+// unencrypted connections. sslMode takes precedence over the legacy
+// requireSsl flag when set.
+func checkCloudSQLRequireSSL(inst sqlInstance) error {
+	ip := inst.Settings.IPConfiguration
+	switch ip.SSLMode {
+	case "ENCRYPTED_ONLY", "TRUSTED_CLIENT_CERTIFICATE_REQUIRED":
+		return nil
+	case "":
+		if ip.RequireSSL {
+			return nil
+		}
+		return fmt.Errorf("requireSsl is false and no sslMode is set")
+	default:
+		return fmt.Errorf("sslMode %s allows unencrypted connections", ip.SSLMode)
+	}
+}
+
+// AssertCloudSQLRequireSSL fails the test if the instance allows non-TLS
+// connections.
+func AssertCloudSQLRequireSSL(t *testing.T, projectID, instance string) {
+	t.Helper()
+
+	inst := describeSQLInstance(t, projectID, instance)
+	ip := inst.Settings.IPConfiguration
+	t.Logf("Cloud SQL %s sslMode=%q requireSsl=%t", instance, ip.SSLMode, ip.RequireSSL)
+	if err := checkCloudSQLRequireSSL(inst); err != nil {
+		t.Errorf("Cloud SQL instance %s: %v", instance, err)
+	}
+}
+
+// checkMaintenanceWindow returns the differences between the instance's
+// maintenance window and the expected day (1 = Monday ... 7 = Sunday, or 0
+// to accept any day) and UTC hour. The API reports an instance without a
+// window as day 0, so that is never a configured window.
+func checkMaintenanceWindow(inst sqlInstance, expectDay, expectHour int) []string {
+	window := inst.Settings.MaintenanceWindow
+	if window == nil || window.Day == 0 {
+		return []string{"no maintenance window is configured"}
+	}
+
+	var problems []string
+	if expectDay != 0 && window.Day != expectDay {
+		problems = append(problems, fmt.Sprintf("maintenance day is %d, want %d", window.Day, expectDay))
+	}
+	if window.Hour != expectHour {
+		problems = append(problems, fmt.Sprintf("maintenance hour is %d, want %d", window.Hour, expectHour))
+	}
+	return problems
+}
+
+// AssertMaintenanceWindow fails the test unless the instance has a
+// maintenance window on expectDay (1 = Monday ... 7 = Sunday, or 0 for any
+// day) at expectHour UTC.
+func AssertMaintenanceWindow(t *testing.T, projectID, instance string, expectDay int, expectHour int) {
+	t.Helper()
+
+	inst := describeSQLInstance(t, projectID, instance)
+	if window := inst.Settings.MaintenanceWindow; window != nil && window.Day != 0 {
+		t.Logf("Cloud SQL %s maintenance window: day=%d hour=%d", instance, window.Day, window.Hour)
+	} else {
+		t.Logf("Cloud SQL %s has no maintenance window", instance)
+	}
+	for _, p := range checkMaintenanceWindow(inst, expectDay, expectHour) {
+		t.Errorf("Cloud SQL instance %s: %s", instance, p)
+	}
+}
+
+// distinct returns the sorted unique values of s.
+func distinct(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// compareReplicaRegions returns the expected regions without a replica and
+// the replica regions that were not expected. Regions compare as sets.
+func compareReplicaRegions(actual, expected []string) (missing, extra []string) {
+	actual, expected = distinct(actual), distinct(expected)
+	return missingFrom(expected, actual), missingFrom(actual, expected)
+}
+
+// AssertReadReplicas fails the test unless the primary instance's read
+// replicas are in exactly the expectedReplicaRegions, reporting missing and
+// extra regions.
+func AssertReadReplicas(t *testing.T, projectID, primaryInstance string, expectedReplicaRegions []string) {
+	t.Helper()
+
+	primary := describeSQLInstance(t, projectID, primaryInstance)
+	regions := make([]string, 0, len(primary.ReplicaNames))
+	for _, name := range primary.ReplicaNames {
+		replica := describeSQLInstance(t, projectID, name)
+		t.Logf("Cloud SQL %s replica %s is in %s", primaryInstance, name, replica.Region)
+		regions = append(regions, replica.Region)
+	}
+	if len(regions) == 0 {
+		t.Logf("Cloud SQL %s has no read replicas", primaryInstance)
+	}
+
+	missing, extra := compareReplicaRegions(regions, expectedReplicaRegions)
+	for _, r := range missing {
+		t.Errorf("Cloud SQL instance %s has no read replica in %s", primaryInstance, r)
+	}
+	for _, r := range extra {
+		t.Errorf("Cloud SQL instance %s has an unexpected read replica in %s", primaryInstance, r)
+	}
+}
@@ -0,0 +1,113 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodeSQLInstance(t *testing.T, raw string) sqlInstance {
+	t.Helper()
+	var inst sqlInstance
+	if err := json.Unmarshal([]byte(raw), &inst); err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+func TestCheckCloudSQLPITR(t *testing.T) {
+	cases := []struct {
+		name             string
+		raw              string
+		requireBinaryLog bool
+		wantProblems     int
+	}{
+		{"mysql with binlog", `{"databaseVersion":"MYSQL_8_0","settings":{"backupConfiguration":{
+			"enabled":true,"binaryLogEnabled":true,"backupRetentionSettings":{"retainedBackups":7}}}}`, true, 0},
+		{"mysql without binlog", `{"databaseVersion":"MYSQL_8_0","settings":{"backupConfiguration":{
+			"enabled":true,"backupRetentionSettings":{"retainedBackups":7}}}}`, true, 1},
+		{"mysql without binlog not required", `{"databaseVersion":"MYSQL_8_0","settings":{"backupConfiguration":{
+			"enabled":true,"backupRetentionSettings":{"retainedBackups":7}}}}`, false, 0},
+		{"postgres pitr", `{"databaseVersion":"POSTGRES_15","settings":{"backupConfiguration":{
+			"enabled":true,"pointInTimeRecoveryEnabled":true,"backupRetentionSettings":{"retainedBackups":14}}}}`, true, 0},
+		{"short retention and no backups", `{"databaseVersion":"POSTGRES_15","settings":{"backupConfiguration":{
+			"backupRetentionSettings":{"retainedBackups":3}}}}`, false, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := checkCloudSQLPITR(decodeSQLInstance(t, tc.raw), tc.requireBinaryLog, 7)
+			if len(got) != tc.wantProblems {
+				t.Errorf("checkCloudSQLPITR() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckCloudSQLRequireSSL(t *testing.T) {
+	cases := []struct {
+		ipConfig string
+		wantErr  bool
+	}{
+		{`{"sslMode":"ENCRYPTED_ONLY"}`, false},
+		{`{"sslMode":"TRUSTED_CLIENT_CERTIFICATE_REQUIRED","requireSsl":true}`, false},
+		{`{"sslMode":"ALLOW_UNENCRYPTED_AND_ENCRYPTED"}`, true},
+		{`{"sslMode":"ALLOW_UNENCRYPTED_AND_ENCRYPTED","requireSsl":true}`, true},
+		{`{"requireSsl":true}`, false},
+		{`{}`, true},
+	}
+	for _, tc := range cases {
+		inst := decodeSQLInstance(t, `{"settings":{"ipConfiguration":`+tc.ipConfig+`}}`)
+		if err := checkCloudSQLRequireSSL(inst); (err != nil) != tc.wantErr {
+			t.Errorf("checkCloudSQLRequireSSL(%s) error = %v, wantErr %t", tc.ipConfig, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckMaintenanceWindow(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		expectDay    int
+		wantProblems int
+	}{
+		{"matches", `{"settings":{"maintenanceWindow":{"day":7,"hour":3}}}`, 7, 0},
+		{"midnight", `{"settings":{"maintenanceWindow":{"day":7}}}`, 7, 1},
+		{"wrong day and hour", `{"settings":{"maintenanceWindow":{"day":1,"hour":12}}}`, 7, 2},
+		{"any day expected", `{"settings":{"maintenanceWindow":{"day":2,"hour":3}}}`, 0, 0},
+		{"any day expected wrong hour", `{"settings":{"maintenanceWindow":{"day":2,"hour":5}}}`, 0, 1},
+		{"unconfigured", `{"settings":{"maintenanceWindow":{"day":0,"hour":0}}}`, 7, 1},
+		{"unconfigured but any day expected", `{"settings":{"maintenanceWindow":{"day":0,"hour":0}}}`, 0, 1},
+		{"unset", `{"settings":{}}`, 0, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkMaintenanceWindow(decodeSQLInstance(t, tc.raw), tc.expectDay, 3); len(got) != tc.wantProblems {
+				t.Errorf("checkMaintenanceWindow() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCompareReplicaRegions(t *testing.T) {
+	cases := []struct {
+		name        string
+		actual      []string
+		expected    []string
+		wantMissing []string
+		wantExtra   []string
+	}{
+		{"matches", []string{"europe-west3", "us-central1"}, []string{"us-central1", "europe-west3"}, nil, nil},
+		{"two replicas in one region", []string{"europe-west3", "europe-west3"}, []string{"europe-west3"}, nil, nil},
+		{"missing", []string{"europe-west3"}, []string{"europe-west3", "us-east1"}, []string{"us-east1"}, nil},
+		{"extra", []string{"europe-west3", "asia-east1"}, []string{"europe-west3"}, nil, []string{"asia-east1"}},
+		{"no replicas", nil, []string{"europe-west3"}, []string{"europe-west3"}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			missing, extra := compareReplicaRegions(tc.actual, tc.expected)
+			if !reflect.DeepEqual(missing, tc.wantMissing) || !reflect.DeepEqual(extra, tc.wantExtra) {
+				t.Errorf("compareReplicaRegions() = %v, %v, want %v, %v", missing, extra, tc.wantMissing, tc.wantExtra)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package testhelpers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// typeKind returns the coarse kind of a Terraform type constraint: string,
+// number, bool, list (list, set, tuple) or map (map, object). any, an empty
+// constraint and anything unrecognised yield "".
+func typeKind(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	for _, k := range []struct{ prefix, kind string }{
+		{"string", "string"}, {"number", "number"}, {"bool", "bool"},
+		{"list", "list"}, {"set", "list"}, {"tuple", "list"},
+		{"map", "map"}, {"object", "map"},
+	} {
+		if constraint == k.prefix || strings.HasPrefix(constraint, k.prefix+"(") {
+			return k.kind
+		}
+	}
+	return ""
+}
+
+var (
+	numberLiteralRe  = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	outputVarRe      = regexp.MustCompile(`^var\.([A-Za-z_][A-Za-z0-9_-]*)$`)
+	conversionFuncRe = regexp.MustCompile(`^([a-z]+)\(`)
+)
+
+// conversionKinds maps functions to the kind of value they return.
+var conversionKinds = map[string]string{
+	"tostring": "string", "jsonencode": "string", "format": "string", "join": "string",
+	"tonumber": "number", "length": "number",
+	"tobool": "bool",
+	"tolist": "list", "toset": "list", "concat": "list", "keys": "list", "values": "list",
+	"tomap": "map", "merge": "map", "zipmap": "map",
+}
+
+// outputKind infers the coarse kind of an output's value expression, using
+// the producer's variable types for var. references. It returns "" when the
+// kind can't be known without evaluating the configuration, e.g. for
+// resource attributes.
+func outputKind(value string, variables map[string]tfBlock) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return ""
+	case value[0] == '"' || strings.HasPrefix(value, "<<"):
+		return "string"
+	case value == "true" || value == "false":
+		return "bool"
+	case numberLiteralRe.MatchString(value):
+		return "number"
+	case value[0] == '[':
+		return "list"
+	case value[0] == '{':
+		return "map"
+	}
+	if m := outputVarRe.FindStringSubmatch(value); m != nil {
+		if v, ok := variables[m[1]]; ok {
+			return typeKind(v.Attributes["type"])
+		}
+	}
+	if m := conversionFuncRe.FindStringSubmatch(value); m != nil {
+		return conversionKinds[m[1]]
+	}
+	return ""
+}
+
+// kindsCompatible reports whether a value of kind from can be passed to a
+// variable of kind to. Unknown kinds are assumed compatible, and Terraform
+// converts numbers and bools to strings.
+func kindsCompatible(from, to string) bool {
+	if from == "" || to == "" || from == to {
+		return true
+	}
+	return to == "string" && (from == "number" || from == "bool")
+}
+
+// blocksByName indexes the single-label blocks of blockType by label.
+func blocksByName(blocks []tfBlock, blockType string) map[string]tfBlock {
+	byName := make(map[string]tfBlock)
+	for _, b := range blocksOfType(blocks, blockType) {
+		if len(b.Labels) == 1 {
+			byName[b.Labels[0]] = b
+		}
+	}
+	return byName
+}
+
+// outputVariableMismatches returns, sorted by output, the problems with
+// feeding each producer output in mapping to its consumer variable: either
+// side undeclared, or kinds that don't convert.
+func outputVariableMismatches(producer, consumer []tfBlock, mapping map[string]string) []string {
+	outputs := blocksByName(producer, "output")
+	producerVars := blocksByName(producer, "variable")
+	consumerVars := blocksByName(consumer, "variable")
+
+	names := make([]string, 0, len(mapping))
+	for name := range mapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, outName := range names {
+		varName := mapping[outName]
+		out, hasOutput := outputs[outName]
+		v, hasVar := consumerVars[varName]
+		if !hasOutput {
+			problems = append(problems, fmt.Sprintf("output %q is not declared by the producer", outName))
+		}
+		if !hasVar {
+			problems = append(problems, fmt.Sprintf("variable %q is not declared by the consumer", varName))
+		}
+		if !hasOutput || !hasVar {
+			continue
+		}
+		from := outputKind(out.Attributes["value"], producerVars)
+		to := typeKind(v.Attributes["type"])
+		if !kindsCompatible(from, to) {
+			problems = append(problems, fmt.Sprintf("output %q is a %s but variable %q is %s", outName, from, varName, v.Attributes["type"]))
+		}
+	}
+	return problems
+}
+
+// AssertOutputVariableCompatibility fails the test for every pair in mapping
+// (producer output name to consumer variable name) where the output or
+// variable is not declared, or the output's value can't be passed to the
+// variable's type. Output types are inferred from literals, var. references
+// and conversion functions; outputs of other expressions are only checked
+// for existence.
+func AssertOutputVariableCompatibility(t *testing.T, producerPath, consumerPath string, mapping map[string]string) {
+	t.Helper()
+
+	producer, err := parseModuleBlocks(producerPath)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", producerPath, err)
+	}
+	consumer, err := parseModuleBlocks(consumerPath)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", consumerPath, err)
+	}
+	for _, p := range outputVariableMismatches(producer, consumer, mapping) {
+		t.Errorf("%s -> %s: %s", producerPath, consumerPath, p)
+	}
+}
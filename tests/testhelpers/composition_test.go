@@ -0,0 +1,109 @@
+package testhelpers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTypeKind(t *testing.T) {
+	cases := map[string]string{
+		"string":                  "string",
+		"list(string)":            "list",
+		"set(string)":             "list",
+		"map(object({":            "map",
+		"object({":                "map",
+		"any":                     "",
+		"":                        "",
+		"stringy":                 "",
+		"tuple([string, number])": "list",
+	}
+	for constraint, want := range cases {
+		if got := typeKind(constraint); got != want {
+			t.Errorf("typeKind(%q) = %q, want %q", constraint, got, want)
+		}
+	}
+}
+
+func TestOutputVariableMismatches(t *testing.T) {
+	producer := t.TempDir()
+	writeFile(t, filepath.Join(producer, "variables.tf"), `variable "subnets" {
+  type = list(string)
+}
+`)
+	writeFile(t, filepath.Join(producer, "outputs.tf"), `output "network_name" {
+  value = google_compute_network.vpc.name
+}
+
+output "subnet_names" {
+  value = var.subnets
+}
+
+output "subnet_count" {
+  value = length(var.subnets)
+}
+
+output "labels" {
+  value = {
+    team = "platform"
+  }
+}
+`)
+	consumer := t.TempDir()
+	writeFile(t, filepath.Join(consumer, "variables.tf"), `variable "network_name" {
+  type = string
+}
+
+variable "subnets" {
+  type = list(string)
+}
+
+variable "replicas" {
+  type = string
+}
+
+variable "labels" {
+  type = map(string)
+}
+
+variable "zones" {
+  type = list(string)
+}
+`)
+	producerBlocks, err := parseModuleBlocks(producer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumerBlocks, err := parseModuleBlocks(consumer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compatible := map[string]string{
+		"network_name": "network_name",
+		"subnet_names": "subnets",
+		"subnet_count": "replicas",
+		"labels":       "labels",
+	}
+	if got := outputVariableMismatches(producerBlocks, consumerBlocks, compatible); len(got) != 0 {
+		t.Errorf("outputVariableMismatches() = %v, want none", got)
+	}
+
+	incompatible := map[string]string{
+		"subnet_names": "network_name",
+		"labels":       "zones",
+		"subnet_ids":   "subnets",
+		"subnet_count": "region",
+	}
+	want := []string{
+		`output "labels" is a map but variable "zones" is list(string)`,
+		`variable "region" is not declared by the consumer`,
+		`output "subnet_ids" is not declared by the producer`,
+		`output "subnet_names" is a list but variable "network_name" is string`,
+	}
+	if got := outputVariableMismatches(producerBlocks, consumerBlocks, incompatible); !reflect.DeepEqual(got, want) {
+		t.Errorf("outputVariableMismatches() = %v, want %v", got, want)
+	}
+
+	AssertOutputVariableCompatibility(t, producer, consumer, compatible)
+}
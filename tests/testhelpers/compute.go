@@ -0,0 +1,504 @@
+package testhelpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// managedInstanceGroup mirrors the fields of a regional managed instance
+// group used by the assertions in this file.
+type managedInstanceGroup struct {
+	Name               string `json:"name"`
+	DistributionPolicy struct {
+		Zones []struct {
+			Zone string `json:"zone"`
+		} `json:"zones"`
+	} `json:"distributionPolicy"`
+	UpdatePolicy struct {
+		Type           string     `json:"type"`
+		MaxSurge       fixedOrPct `json:"maxSurge"`
+		MaxUnavailable fixedOrPct `json:"maxUnavailable"`
+	} `json:"updatePolicy"`
+	AutoHealingPolicies []struct {
+		HealthCheck     string `json:"healthCheck"`
+		InitialDelaySec int    `json:"initialDelaySec"`
+	} `json:"autoHealingPolicies"`
+}
+
+// fixedOrPct is a MIG update policy bound, given either as an absolute
+// instance count or as a percentage.
+type fixedOrPct struct {
+	Fixed   *int `json:"fixed"`
+	Percent *int `json:"percent"`
+}
+
+func (f fixedOrPct) String() string {
+	switch {
+	case f.Percent != nil:
+		return fmt.Sprintf("%d%%", *f.Percent)
+	case f.Fixed != nil:
+		return fmt.Sprint(*f.Fixed)
+	}
+	return "unset"
+}
+
+// matchesFixed reports whether the bound is an absolute count equal to want.
+func (f fixedOrPct) matchesFixed(want int) bool {
+	return f.Percent == nil && f.Fixed != nil && *f.Fixed == want
+}
+
+func describeRegionalMIG(t *testing.T, projectID, region, mig string) managedInstanceGroup {
+	t.Helper()
+
+	var group managedInstanceGroup
+	if err := gcloudJSON(&group, "compute", "instance-groups", "managed", "describe", mig,
+		"--region", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe managed instance group %s: %v", mig, err)
+	}
+	return group
+}
+
+// migZones returns the zone names covered by the group's distribution policy.
+func migZones(group managedInstanceGroup) []string {
+	zones := make([]string, 0, len(group.DistributionPolicy.Zones))
+	for _, z := range group.DistributionPolicy.Zones {
+		zones = append(zones, lastSegment(z.Zone))
+	}
+	return zones
+}
+
+// checkMIGZoneSpread returns an error if fewer than minZones distinct zones
+// are in use.
+func checkMIGZoneSpread(zones []string, minZones int) error {
+	distinct := make(map[string]bool, len(zones))
+	for _, z := range zones {
+		distinct[z] = true
+	}
+	if len(distinct) < minZones {
+		return fmt.Errorf("distribution policy covers %d zone(s) [%s], want at least %d",
+			len(distinct), strings.Join(zones, ", "), minZones)
+	}
+	return nil
+}
+
+// AssertMIGZoneSpread fails the test if the regional managed instance group
+// distributes instances across fewer than minZones zones.
+func AssertMIGZoneSpread(t *testing.T, projectID, region, mig string, minZones int) {
+	t.Helper()
+
+	zones := migZones(describeRegionalMIG(t, projectID, region, mig))
+	t.Logf("Managed instance group %s uses zones: %s", mig, strings.Join(zones, ", "))
+	if err := checkMIGZoneSpread(zones, minZones); err != nil {
+		t.Errorf("Managed instance group %s: %v", mig, err)
+	}
+}
+
+// checkRollingUpdatePolicy returns the differences between the group's update
+// policy and a PROACTIVE policy with the given fixed surge/unavailable bounds.
+func checkRollingUpdatePolicy(group managedInstanceGroup, maxSurge, maxUnavailable int) []string {
+	policy := group.UpdatePolicy
+
+	var problems []string
+	if policy.Type != "PROACTIVE" {
+		problems = append(problems, fmt.Sprintf("update policy type is %q, want PROACTIVE", policy.Type))
+	}
+	if !policy.MaxSurge.matchesFixed(maxSurge) {
+		problems = append(problems, fmt.Sprintf("maxSurge is %s, want %d", policy.MaxSurge, maxSurge))
+	}
+	if !policy.MaxUnavailable.matchesFixed(maxUnavailable) {
+		problems = append(problems, fmt.Sprintf("maxUnavailable is %s, want %d", policy.MaxUnavailable, maxUnavailable))
+	}
+	return problems
+}
+
+// AssertRollingUpdatePolicy fails the test unless the regional managed
+// instance group uses a PROACTIVE update policy with the given fixed
+// maxSurge and maxUnavailable.
+func AssertRollingUpdatePolicy(t *testing.T, projectID, region, mig string, maxSurge, maxUnavailable int) {
+	t.Helper()
+
+	group := describeRegionalMIG(t, projectID, region, mig)
+	t.Logf("Managed instance group %s update policy: type=%s maxSurge=%s maxUnavailable=%s",
+		mig, group.UpdatePolicy.Type, group.UpdatePolicy.MaxSurge, group.UpdatePolicy.MaxUnavailable)
+
+	for _, p := range checkRollingUpdatePolicy(group, maxSurge, maxUnavailable) {
+		t.Errorf("Managed instance group %s: %s", mig, p)
+	}
+}
+
+// checkAutohealing returns the differences between the group's autohealing
+// policy and one using expectHealthCheck with initialDelaySec. The health
+// check may be given by name or URL.
+func checkAutohealing(group managedInstanceGroup, expectHealthCheck string, initialDelaySec int) []string {
+	if len(group.AutoHealingPolicies) == 0 {
+		return []string{"autohealing is not configured"}
+	}
+	policy := group.AutoHealingPolicies[0]
+
+	var problems []string
+	if actual := lastSegment(policy.HealthCheck); actual != lastSegment(expectHealthCheck) {
+		problems = append(problems, fmt.Sprintf("autohealing health check is %q, want %q", actual, lastSegment(expectHealthCheck)))
+	}
+	if policy.InitialDelaySec != initialDelaySec {
+		problems = append(problems, fmt.Sprintf("autohealing initial delay is %ds, want %ds", policy.InitialDelaySec, initialDelaySec))
+	}
+	return problems
+}
+
+// AssertAutohealing fails the test unless the regional managed instance
+// group autoheals using expectHealthCheck with an initial delay of
+// initialDelaySec seconds.
+func AssertAutohealing(t *testing.T, projectID, region, mig string, expectHealthCheck string, initialDelaySec int) {
+	t.Helper()
+
+	group := describeRegionalMIG(t, projectID, region, mig)
+	for _, p := range group.AutoHealingPolicies {
+		t.Logf("Managed instance group %s autohealing: healthCheck=%s initialDelaySec=%d",
+			mig, lastSegment(p.HealthCheck), p.InitialDelaySec)
+	}
+
+	for _, p := range checkAutohealing(group, expectHealthCheck, initialDelaySec) {
+		t.Errorf("Managed instance group %s: %s", mig, p)
+	}
+}
+
+// ConfidentialMachineFamilies are the machine series that support
+// Confidential VM (AMD SEV/SEV-SNP or Intel TDX).
+var ConfidentialMachineFamilies = []string{"n2d", "c2d", "c3d", "c3", "a3"}
+
+// computeInstance mirrors the fields of a Compute Engine instance used by the
+// assertions in this file.
+type computeInstance struct {
+	Name                       string `json:"name"`
+	MachineType                string `json:"machineType"`
+	ConfidentialInstanceConfig *struct {
+		EnableConfidentialCompute bool   `json:"enableConfidentialCompute"`
+		ConfidentialInstanceType  string `json:"confidentialInstanceType"`
+	} `json:"confidentialInstanceConfig"`
+	Metadata struct {
+		Items []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"items"`
+	} `json:"metadata"`
+	Tags struct {
+		Items []string `json:"items"`
+	} `json:"tags"`
+	Scheduling struct {
+		ProvisioningModel string `json:"provisioningModel"`
+		Preemptible       bool   `json:"preemptible"`
+		// AutomaticRestart defaults to true when absent.
+		AutomaticRestart  *bool  `json:"automaticRestart"`
+		OnHostMaintenance string `json:"onHostMaintenance"`
+	} `json:"scheduling"`
+	Disks []struct {
+		Boot       bool   `json:"boot"`
+		AutoDelete bool   `json:"autoDelete"`
+		Source     string `json:"source"`
+	} `json:"disks"`
+}
+
+func describeInstance(t *testing.T, projectID, zone, instance string) computeInstance {
+	t.Helper()
+
+	var inst computeInstance
+	if err := gcloudJSON(&inst, "compute", "instances", "describe", instance,
+		"--zone", zone, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe instance %s: %v", instance, err)
+	}
+	return inst
+}
+
+// confidentialType returns the instance's Confidential VM technology (SEV,
+// SEV_SNP or TDX), or "" if confidential computing is off. Instances created
+// with only the legacy enableConfidentialCompute flag use SEV.
+func (i computeInstance) confidentialType() string {
+	c := i.ConfidentialInstanceConfig
+	if c == nil {
+		return ""
+	}
+	if c.ConfidentialInstanceType != "" && c.ConfidentialInstanceType != "CONFIDENTIAL_INSTANCE_TYPE_UNSPECIFIED" {
+		return c.ConfidentialInstanceType
+	}
+	if c.EnableConfidentialCompute {
+		return "SEV"
+	}
+	return ""
+}
+
+func (i computeInstance) confidentialCompute() bool {
+	return i.confidentialType() != ""
+}
+
+// machineFamily returns the series of a machine type, e.g. n2d for
+// n2d-standard-4 or a machine type URL.
+func machineFamily(machineType string) string {
+	family, _, _ := strings.Cut(lastSegment(machineType), "-")
+	return strings.ToLower(family)
+}
+
+// checkConfidentialVM returns the reasons the instance is not a
+// Confidential VM.
+func checkConfidentialVM(inst computeInstance, families []string) []string {
+	var problems []string
+	if !inst.confidentialCompute() {
+		problems = append(problems, "confidential computing is not enabled")
+	}
+	family := machineFamily(inst.MachineType)
+	supported := false
+	for _, f := range families {
+		if f == family {
+			supported = true
+		}
+	}
+	if !supported {
+		problems = append(problems, fmt.Sprintf("machine type %s does not support Confidential VM", lastSegment(inst.MachineType)))
+	}
+	return problems
+}
+
+// AssertConfidentialVM fails the test unless the instance has confidential
+// computing enabled on a machine type from ConfidentialMachineFamilies.
+func AssertConfidentialVM(t *testing.T, projectID, zone, instance string) {
+	t.Helper()
+
+	inst := describeInstance(t, projectID, zone, instance)
+	t.Logf("Instance %s machine type=%s confidential=%q", instance, lastSegment(inst.MachineType), inst.confidentialType())
+	for _, p := range checkConfidentialVM(inst, ConfidentialMachineFamilies) {
+		t.Errorf("Instance %s: %s", instance, p)
+	}
+}
+
+// metadataValue returns the instance metadata value for key.
+func (i computeInstance) metadataValue(key string) (string, bool) {
+	for _, item := range i.Metadata.Items {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkStartupScript returns the SHA-256 of the instance's startup-script
+// metadata and an error if it is absent or differs from expectedSHA256.
+func checkStartupScript(inst computeInstance, expectedSHA256 string) (string, error) {
+	script, ok := inst.metadataValue("startup-script")
+	if !ok {
+		return "", fmt.Errorf("no startup-script metadata")
+	}
+	actual := sha256Hex(script)
+	if !strings.EqualFold(actual, expectedSHA256) {
+		return actual, fmt.Errorf("startup-script SHA-256 is %s, want %s", actual, expectedSHA256)
+	}
+	return actual, nil
+}
+
+// AssertStartupScript fails the test if the instance has no startup-script
+// metadata or its SHA-256 differs from expectedSHA256.
+func AssertStartupScript(t *testing.T, projectID, zone, instance string, expectedSHA256 string) {
+	t.Helper()
+
+	actual, err := checkStartupScript(describeInstance(t, projectID, zone, instance), expectedSHA256)
+	if actual != "" {
+		t.Logf("Instance %s startup-script SHA-256: %s", instance, actual)
+	}
+	if err != nil {
+		t.Errorf("Instance %s: %v", instance, err)
+	}
+}
+
+// persistentDisk mirrors the fields of a Compute Engine persistent disk used
+// by the assertions in this file.
+type persistentDisk struct {
+	Name         string   `json:"name"`
+	Zone         string   `json:"zone"`
+	Region       string   `json:"region"`
+	ReplicaZones []string `json:"replicaZones"`
+	// DiskEncryptionKey is absent for Google-managed encryption. CMEK disks
+	// set KMSKeyName; CSEK disks only report the key's SHA-256.
+	DiskEncryptionKey *struct {
+		KMSKeyName string `json:"kmsKeyName"`
+		SHA256     string `json:"sha256"`
+	} `json:"diskEncryptionKey"`
+}
+
+// encryption describes how the disk is encrypted: "CMEK", "CSEK" or
+// "Google-managed".
+func (d persistentDisk) encryption() string {
+	switch {
+	case d.DiskEncryptionKey != nil && d.DiskEncryptionKey.KMSKeyName != "":
+		return "CMEK"
+	case d.DiskEncryptionKey != nil && d.DiskEncryptionKey.SHA256 != "":
+		return "CSEK"
+	}
+	return "Google-managed"
+}
+
+// replicaZoneNames returns the zone names the disk is replicated to.
+func (d persistentDisk) replicaZoneNames() []string {
+	zones := make([]string, 0, len(d.ReplicaZones))
+	for _, z := range d.ReplicaZones {
+		zones = append(zones, lastSegment(z))
+	}
+	return zones
+}
+
+// checkRegionalDisk returns the reasons the disk is not a regional disk
+// replicated across expectedReplicaZones zones.
+func checkRegionalDisk(disk persistentDisk, expectedReplicaZones int) []string {
+	var problems []string
+	if disk.Region == "" {
+		location := "unknown location"
+		if disk.Zone != "" {
+			location = "zone " + lastSegment(disk.Zone)
+		}
+		problems = append(problems, fmt.Sprintf("disk is zonal (%s), want a regional disk", location))
+	}
+	if n := len(disk.ReplicaZones); n != expectedReplicaZones {
+		problems = append(problems, fmt.Sprintf("disk is replicated across %d zone(s), want %d", n, expectedReplicaZones))
+	}
+	return problems
+}
+
+// AssertRegionalDisk fails the test unless the disk is a regional persistent
+// disk replicated across expectedReplicaZones zones.
+func AssertRegionalDisk(t *testing.T, projectID, region, disk string, expectedReplicaZones int) {
+	t.Helper()
+
+	var d persistentDisk
+	if err := gcloudJSON(&d, "compute", "disks", "describe", disk,
+		"--region", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe disk %s: %v", disk, err)
+	}
+	t.Logf("Disk %s region=%s replica zones: %s", disk, lastSegment(d.Region), strings.Join(d.replicaZoneNames(), ", "))
+	for _, p := range checkRegionalDisk(d, expectedReplicaZones) {
+		t.Errorf("Disk %s: %s", disk, p)
+	}
+}
+
+// checkDiskEncryption returns the reasons the disk's encryption is not
+// acceptable: with requireCMEK it must use a Cloud KMS key, and any KMS key
+// must come from one of allowedKeyRings.
+func checkDiskEncryption(disk persistentDisk, requireCMEK bool, allowedKeyRings []string) []string {
+	var problems []string
+	if requireCMEK && disk.encryption() != "CMEK" {
+		problems = append(problems, fmt.Sprintf("disk uses %s encryption, want CMEK", disk.encryption()))
+	}
+	if disk.encryption() == "CMEK" && !keyRingAllowed(disk.DiskEncryptionKey.KMSKeyName, allowedKeyRings) {
+		problems = append(problems, fmt.Sprintf("KMS key %s is not in an allowed key ring", disk.DiskEncryptionKey.KMSKeyName))
+	}
+	return problems
+}
+
+// AssertDiskEncryption fails the test if the zonal disk is not encrypted
+// with a customer-managed key while requireCMEK is set, or if its KMS key is
+// outside AllowedKMSKeyRings.
+func AssertDiskEncryption(t *testing.T, projectID, zone, disk string, requireCMEK bool) {
+	t.Helper()
+
+	var d persistentDisk
+	if err := gcloudJSON(&d, "compute", "disks", "describe", disk,
+		"--zone", zone, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe disk %s: %v", disk, err)
+	}
+	if d.encryption() == "CMEK" {
+		t.Logf("Disk %s encryption: CMEK with %s", disk, d.DiskEncryptionKey.KMSKeyName)
+	} else {
+		t.Logf("Disk %s encryption: %s", disk, d.encryption())
+	}
+	for _, p := range checkDiskEncryption(d, requireCMEK, AllowedKMSKeyRings) {
+		t.Errorf("Disk %s: %s", disk, p)
+	}
+}
+
+// missingNetworkTags returns the required network tags the instance lacks.
+func missingNetworkTags(inst computeInstance, requiredTags []string) []string {
+	return missingFrom(requiredTags, inst.Tags.Items)
+}
+
+// AssertNetworkTags fails the test listing every required network tag
+// missing from the instance. Network tags select the firewall rules that
+// apply to an instance and are unrelated to labels.
+func AssertNetworkTags(t *testing.T, projectID, zone, instance string, requiredTags []string) {
+	t.Helper()
+
+	inst := describeInstance(t, projectID, zone, instance)
+	t.Logf("Instance %s network tags: %s", instance, strings.Join(inst.Tags.Items, ", "))
+	if missing := missingNetworkTags(inst, requiredTags); len(missing) > 0 {
+		t.Errorf("Instance %s is missing network tags: %s", instance, strings.Join(missing, ", "))
+	}
+}
+
+// spot reports whether the instance is a Spot or legacy preemptible VM.
+func (i computeInstance) spot() bool {
+	return i.Scheduling.ProvisioningModel == "SPOT" || i.Scheduling.Preemptible
+}
+
+func (i computeInstance) automaticRestart() bool {
+	return i.Scheduling.AutomaticRestart == nil || *i.Scheduling.AutomaticRestart
+}
+
+// checkInstanceScheduling returns the differences between the instance's
+// scheduling and the expected Spot and automatic restart settings.
+func checkInstanceScheduling(inst computeInstance, expectSpot, expectAutoRestart bool) []string {
+	var problems []string
+	if inst.spot() != expectSpot {
+		problems = append(problems, fmt.Sprintf("spot/preemptible is %t, want %t", inst.spot(), expectSpot))
+	}
+	if inst.automaticRestart() != expectAutoRestart {
+		problems = append(problems, fmt.Sprintf("automatic restart is %t, want %t", inst.automaticRestart(), expectAutoRestart))
+	}
+	return problems
+}
+
+// AssertInstanceScheduling fails the test unless the instance's scheduling
+// is Spot (or preemptible) exactly when expectSpot is set and restarts
+// automatically exactly when expectAutoRestart is set.
+func AssertInstanceScheduling(t *testing.T, projectID, zone, instance string, expectSpot bool, expectAutoRestart bool) {
+	t.Helper()
+
+	inst := describeInstance(t, projectID, zone, instance)
+	t.Logf("Instance %s scheduling: provisioningModel=%s preemptible=%t automaticRestart=%t onHostMaintenance=%s",
+		instance, inst.Scheduling.ProvisioningModel, inst.Scheduling.Preemptible, inst.automaticRestart(), inst.Scheduling.OnHostMaintenance)
+	for _, p := range checkInstanceScheduling(inst, expectSpot, expectAutoRestart) {
+		t.Errorf("Instance %s: %s", instance, p)
+	}
+}
+
+// checkBootDiskAutoDelete returns an error unless the instance's boot disk
+// is deleted with the instance exactly when expectAutoDelete is set.
+func checkBootDiskAutoDelete(inst computeInstance, expectAutoDelete bool) error {
+	for _, d := range inst.Disks {
+		if !d.Boot {
+			continue
+		}
+		if d.AutoDelete != expectAutoDelete {
+			return fmt.Errorf("boot disk %s auto-delete is %t, want %t", lastSegment(d.Source), d.AutoDelete, expectAutoDelete)
+		}
+		return nil
+	}
+	return fmt.Errorf("no boot disk attached")
+}
+
+// AssertDiskAutoDelete fails the test unless the instance's boot disk
+// auto-delete setting is expectBootAutoDelete, i.e. whether the disk is
+// deleted along with the instance.
+func AssertDiskAutoDelete(t *testing.T, projectID, zone, instance string, expectBootAutoDelete bool) {
+	t.Helper()
+
+	inst := describeInstance(t, projectID, zone, instance)
+	for _, d := range inst.Disks {
+		t.Logf("Instance %s disk %s boot=%t autoDelete=%t", instance, lastSegment(d.Source), d.Boot, d.AutoDelete)
+	}
+	if err := checkBootDiskAutoDelete(inst, expectBootAutoDelete); err != nil {
+		t.Errorf("Instance %s: %v", instance, err)
+	}
+}
@@ -0,0 +1,260 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMIGZones(t *testing.T) {
+	var group managedInstanceGroup
+	raw := `{"name":"web","distributionPolicy":{"zones":[
+		{"zone":"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-b"},
+		{"zone":"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-c"}]}}`
+	if err := json.Unmarshal([]byte(raw), &group); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"europe-west1-b", "europe-west1-c"}
+	if got := migZones(group); !reflect.DeepEqual(got, want) {
+		t.Errorf("migZones() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckMIGZoneSpread(t *testing.T) {
+	cases := []struct {
+		name     string
+		zones    []string
+		minZones int
+		wantErr  bool
+	}{
+		{"meets minimum", []string{"a", "b", "c"}, 3, false},
+		{"too few", []string{"a", "b"}, 3, true},
+		{"duplicates not counted", []string{"a", "a", "b"}, 3, true},
+		{"empty", nil, 1, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkMIGZoneSpread(tc.zones, tc.minZones); (err != nil) != tc.wantErr {
+				t.Errorf("checkMIGZoneSpread() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRollingUpdatePolicy(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		wantProblems int
+	}{
+		{"matches", `{"updatePolicy":{"type":"PROACTIVE","maxSurge":{"fixed":3},"maxUnavailable":{"fixed":0}}}`, 0},
+		{"opportunistic", `{"updatePolicy":{"type":"OPPORTUNISTIC","maxSurge":{"fixed":3},"maxUnavailable":{"fixed":0}}}`, 1},
+		{"percent surge", `{"updatePolicy":{"type":"PROACTIVE","maxSurge":{"percent":20},"maxUnavailable":{"fixed":0}}}`, 1},
+		{"wrong values", `{"updatePolicy":{"type":"PROACTIVE","maxSurge":{"fixed":1},"maxUnavailable":{"fixed":1}}}`, 2},
+		{"unset", `{}`, 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var group managedInstanceGroup
+			if err := json.Unmarshal([]byte(tc.raw), &group); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkRollingUpdatePolicy(group, 3, 0); len(got) != tc.wantProblems {
+				t.Errorf("checkRollingUpdatePolicy() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckAutohealing(t *testing.T) {
+	const hc = "https://www.googleapis.com/compute/v1/projects/p/global/healthChecks/web-hc"
+	cases := []struct {
+		name         string
+		raw          string
+		wantProblems int
+	}{
+		{"matches", `{"autoHealingPolicies":[{"healthCheck":"` + hc + `","initialDelaySec":300}]}`, 0},
+		{"other health check", `{"autoHealingPolicies":[{"healthCheck":"projects/p/global/healthChecks/tcp-hc","initialDelaySec":300}]}`, 1},
+		{"wrong delay", `{"autoHealingPolicies":[{"healthCheck":"` + hc + `","initialDelaySec":60}]}`, 1},
+		{"not configured", `{}`, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var group managedInstanceGroup
+			if err := json.Unmarshal([]byte(tc.raw), &group); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkAutohealing(group, "web-hc", 300); len(got) != tc.wantProblems {
+				t.Errorf("checkAutohealing() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func decodeInstance(t *testing.T, raw string) computeInstance {
+	t.Helper()
+	var inst computeInstance
+	if err := json.Unmarshal([]byte(raw), &inst); err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+func TestCheckConfidentialVM(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		wantProblems int
+	}{
+		{"n2d confidential", `{"machineType":"https://www.googleapis.com/compute/v1/projects/p/zones/z/machineTypes/n2d-standard-4",
+			"confidentialInstanceConfig":{"enableConfidentialCompute":true}}`, 0},
+		{"c3 confidential", `{"machineType":"c3-standard-8","confidentialInstanceConfig":{"enableConfidentialCompute":true}}`, 0},
+		{"c3 tdx", `{"machineType":"c3-standard-4","confidentialInstanceConfig":{"confidentialInstanceType":"TDX"}}`, 0},
+		{"n2d sev-snp", `{"machineType":"n2d-standard-4","confidentialInstanceConfig":{"confidentialInstanceType":"SEV_SNP"}}`, 0},
+		{"n2d unspecified type", `{"machineType":"n2d-standard-4",
+			"confidentialInstanceConfig":{"confidentialInstanceType":"CONFIDENTIAL_INSTANCE_TYPE_UNSPECIFIED"}}`, 1},
+		{"n2d not confidential", `{"machineType":"n2d-standard-4"}`, 1},
+		{"e2 confidential flag", `{"machineType":"e2-medium","confidentialInstanceConfig":{"enableConfidentialCompute":true}}`, 1},
+		{"e2 plain", `{"machineType":"e2-medium"}`, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkConfidentialVM(decodeInstance(t, tc.raw), ConfidentialMachineFamilies); len(got) != tc.wantProblems {
+				t.Errorf("checkConfidentialVM() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckStartupScript(t *testing.T) {
+	const script = "#!/bin/bash\napt-get update\n"
+	want := sha256Hex(script)
+
+	inst := decodeInstance(t, `{"metadata":{"items":[{"key":"enable-oslogin","value":"TRUE"},{"key":"startup-script","value":"#!/bin/bash\napt-get update\n"}]}}`)
+	if got, err := checkStartupScript(inst, strings.ToUpper(want)); err != nil || got != want {
+		t.Errorf("checkStartupScript() = %s, %v, want %s", got, err, want)
+	}
+
+	if _, err := checkStartupScript(inst, sha256Hex("tampered")); err == nil {
+		t.Error("expected a hash mismatch")
+	}
+	if _, err := checkStartupScript(decodeInstance(t, `{}`), want); err == nil {
+		t.Error("expected an error for a missing startup-script")
+	}
+}
+
+func TestCheckRegionalDisk(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		wantProblems int
+	}{
+		{"regional two zones", `{"name":"data","region":"https://www.googleapis.com/compute/v1/projects/p/regions/europe-west1",
+			"replicaZones":["https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-b",
+			"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-c"]}`, 0},
+		{"regional one zone", `{"region":"europe-west1","replicaZones":["europe-west1-b"]}`, 1},
+		{"zonal", `{"zone":"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-b"}`, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var disk persistentDisk
+			if err := json.Unmarshal([]byte(tc.raw), &disk); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkRegionalDisk(disk, 2); len(got) != tc.wantProblems {
+				t.Errorf("checkRegionalDisk() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckDiskEncryption(t *testing.T) {
+	const ring = "projects/p/locations/europe-west1/keyRings/disks"
+	cases := []struct {
+		name         string
+		raw          string
+		requireCMEK  bool
+		wantProblems int
+	}{
+		{"cmek allowed ring", `{"diskEncryptionKey":{"kmsKeyName":"` + ring + `/cryptoKeys/pd/cryptoKeyVersions/1"}}`, true, 0},
+		{"cmek other ring", `{"diskEncryptionKey":{"kmsKeyName":"projects/p/locations/europe-west1/keyRings/other/cryptoKeys/pd"}}`, false, 1},
+		{"google-managed required", `{}`, true, 1},
+		{"google-managed optional", `{}`, false, 0},
+		{"csek required", `{"diskEncryptionKey":{"sha256":"c2FtcGxl"}}`, true, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var disk persistentDisk
+			if err := json.Unmarshal([]byte(tc.raw), &disk); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkDiskEncryption(disk, tc.requireCMEK, []string{ring}); len(got) != tc.wantProblems {
+				t.Errorf("checkDiskEncryption() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestMissingNetworkTags(t *testing.T) {
+	inst := decodeInstance(t, `{"name":"web-1","tags":{"items":["http-server","allow-iap"],"fingerprint":"abc"},
+		"labels":{"ssh":"true"}}`)
+	if got := missingNetworkTags(inst, []string{"allow-iap", "http-server"}); len(got) != 0 {
+		t.Errorf("missingNetworkTags() = %v, want none", got)
+	}
+	want := []string{"https-server", "ssh"}
+	if got := missingNetworkTags(inst, []string{"ssh", "http-server", "https-server"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("missingNetworkTags() = %v, want %v", got, want)
+	}
+	if got := missingNetworkTags(decodeInstance(t, `{}`), []string{"ssh"}); len(got) != 1 {
+		t.Errorf("untagged instance: missingNetworkTags() = %v", got)
+	}
+}
+
+func TestCheckInstanceScheduling(t *testing.T) {
+	cases := []struct {
+		name              string
+		raw               string
+		expectSpot        bool
+		expectAutoRestart bool
+		wantProblems      int
+	}{
+		{"standard defaults", `{"scheduling":{"provisioningModel":"STANDARD","onHostMaintenance":"MIGRATE"}}`, false, true, 0},
+		{"spot", `{"scheduling":{"provisioningModel":"SPOT","automaticRestart":false,"onHostMaintenance":"TERMINATE"}}`, true, false, 0},
+		{"legacy preemptible", `{"scheduling":{"preemptible":true,"automaticRestart":false}}`, true, false, 0},
+		{"standard expected spot", `{"scheduling":{"provisioningModel":"STANDARD"}}`, true, false, 2},
+		{"restart disabled", `{"scheduling":{"automaticRestart":false}}`, false, true, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := checkInstanceScheduling(decodeInstance(t, tc.raw), tc.expectSpot, tc.expectAutoRestart)
+			if len(got) != tc.wantProblems {
+				t.Errorf("checkInstanceScheduling() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckBootDiskAutoDelete(t *testing.T) {
+	const disks = `{"disks":[
+		{"boot":false,"autoDelete":false,"source":"projects/p/zones/z/disks/data"},
+		{"boot":true,"autoDelete":true,"source":"projects/p/zones/z/disks/boot"}]}`
+	cases := []struct {
+		name             string
+		raw              string
+		expectAutoDelete bool
+		wantErr          bool
+	}{
+		{"auto-deleted boot disk", disks, true, false},
+		{"boot disk expected to be kept", disks, false, true},
+		{"kept boot disk", `{"disks":[{"boot":true,"autoDelete":false,"source":"boot"}]}`, false, false},
+		{"no boot disk", `{"disks":[{"boot":false,"autoDelete":true}]}`, true, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkBootDiskAutoDelete(decodeInstance(t, tc.raw), tc.expectAutoDelete); (err != nil) != tc.wantErr {
+				t.Errorf("checkBootDiskAutoDelete() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
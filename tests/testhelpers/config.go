@@ -0,0 +1,70 @@
+package testhelpers
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	defaultTimeoutEnv      = "TEST_DEFAULT_TIMEOUT"
+	defaultPollIntervalEnv = "TEST_DEFAULT_POLL_INTERVAL"
+	maxPlanDurationEnv     = "TEST_MAX_PLAN_DURATION"
+
+	builtinDefaultTimeout      = 5 * time.Minute
+	builtinDefaultPollInterval = 10 * time.Second
+)
+
+// TestConfig holds run-wide defaults for the polling helpers.
+//
+// A helper resolves its timeout and poll interval in this order:
+//  1. a non-zero value passed by the caller;
+//  2. TEST_DEFAULT_TIMEOUT / TEST_DEFAULT_POLL_INTERVAL (Go duration syntax,
+//     e.g. "10m", "30s");
+//  3. the built-in defaults of 5m and 10s.
+//
+// Unparseable or non-positive environment values are ignored.
+type TestConfig struct {
+	DefaultTimeout      time.Duration
+	DefaultPollInterval time.Duration
+
+	// MaxPlanDuration fails MeasurePlanTime when a plan takes longer.
+	// Zero (the default, TEST_MAX_PLAN_DURATION unset) disables the limit.
+	MaxPlanDuration time.Duration
+}
+
+// Config is the TestConfig used by the helpers. It is loaded from the
+// environment at package init and may be overridden by tests.
+var Config = LoadTestConfig()
+
+// LoadTestConfig builds a TestConfig from the environment.
+func LoadTestConfig() TestConfig {
+	return TestConfig{
+		DefaultTimeout:      durationFromEnv(defaultTimeoutEnv, builtinDefaultTimeout),
+		DefaultPollInterval: durationFromEnv(defaultPollIntervalEnv, builtinDefaultPollInterval),
+		MaxPlanDuration:     durationFromEnv(maxPlanDurationEnv, 0),
+	}
+}
+
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// timeout returns d, or the configured default when d is zero.
+func (c TestConfig) timeout(d time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return c.DefaultTimeout
+}
+
+// pollInterval returns d, or the configured default when d is zero.
+func (c TestConfig) pollInterval(d time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return c.DefaultPollInterval
+}
@@ -0,0 +1,36 @@
+package testhelpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTestConfig(t *testing.T) {
+	t.Setenv(defaultTimeoutEnv, "90s")
+	t.Setenv(defaultPollIntervalEnv, "not-a-duration")
+
+	cfg := LoadTestConfig()
+	if cfg.DefaultTimeout != 90*time.Second {
+		t.Errorf("DefaultTimeout = %s, want 90s from env", cfg.DefaultTimeout)
+	}
+	if cfg.DefaultPollInterval != builtinDefaultPollInterval {
+		t.Errorf("DefaultPollInterval = %s, want built-in default for invalid env", cfg.DefaultPollInterval)
+	}
+}
+
+func TestConfigFallbacks(t *testing.T) {
+	cfg := TestConfig{DefaultTimeout: time.Minute, DefaultPollInterval: 5 * time.Second}
+
+	if got := cfg.timeout(0); got != time.Minute {
+		t.Errorf("timeout(0) = %s, want config default", got)
+	}
+	if got := cfg.timeout(3 * time.Second); got != 3*time.Second {
+		t.Errorf("timeout(3s) = %s, want caller value", got)
+	}
+	if got := cfg.pollInterval(0); got != 5*time.Second {
+		t.Errorf("pollInterval(0) = %s, want config default", got)
+	}
+	if got := cfg.pollInterval(time.Second); got != time.Second {
+		t.Errorf("pollInterval(1s) = %s, want caller value", got)
+	}
+}
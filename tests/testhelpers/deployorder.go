@@ -0,0 +1,48 @@
+package testhelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// checkDeployOrder returns an error describing the first dependency the
+// actual order violates, scanning actualOrder from the start.
+// requiredBefore maps a resource to the resources that must be deployed
+// before it.
+func checkDeployOrder(actualOrder []string, requiredBefore map[string][]string) error {
+	position := make(map[string]int, len(actualOrder))
+	for i, r := range actualOrder {
+		if _, dup := position[r]; !dup {
+			position[r] = i
+		}
+	}
+
+	for i, r := range actualOrder {
+		deps := append([]string(nil), requiredBefore[r]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			p, ok := position[dep]
+			if !ok {
+				return fmt.Errorf("%s was deployed but its dependency %s never was", r, dep)
+			}
+			if p > i {
+				return fmt.Errorf("%s (position %d) was deployed before its dependency %s (position %d)", r, i+1, dep, p+1)
+			}
+		}
+	}
+	return nil
+}
+
+// AssertDeployedInOrder fails the test with the first violated pair if
+// actualOrder, the order resources were actually deployed in, does not
+// deploy every resource after the ones requiredBefore lists for it.
+func AssertDeployedInOrder(t *testing.T, actualOrder []string, requiredBefore map[string][]string) {
+	t.Helper()
+
+	t.Logf("Deploy order: %s", strings.Join(actualOrder, " -> "))
+	if err := checkDeployOrder(actualOrder, requiredBefore); err != nil {
+		t.Errorf("Deploy order violates dependencies: %v", err)
+	}
+}
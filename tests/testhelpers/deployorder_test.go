@@ -0,0 +1,36 @@
+package testhelpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckDeployOrder(t *testing.T) {
+	requiredBefore := map[string][]string{
+		"subnets":   {"vpc"},
+		"cloud-sql": {"vpc", "kms"},
+		"gke":       {"subnets", "iam"},
+	}
+	cases := []struct {
+		name    string
+		order   []string
+		wantErr string
+	}{
+		{"valid", []string{"kms", "iam", "vpc", "subnets", "cloud-sql", "gke"}, ""},
+		{"unrelated resources", []string{"vpc", "dns", "subnets"}, ""},
+		{"dependency after", []string{"kms", "subnets", "vpc"}, "subnets (position 2) was deployed before its dependency vpc (position 3)"},
+		{"first violation wins", []string{"gke", "cloud-sql", "iam", "kms", "vpc", "subnets"}, "gke (position 1) was deployed before its dependency iam"},
+		{"missing dependency", []string{"vpc", "cloud-sql"}, "cloud-sql was deployed but its dependency kms never was"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkDeployOrder(tc.order, requiredBefore)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("checkDeployOrder() = %v, want nil", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("checkDeployOrder() = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// managedZone mirrors the fields of a Cloud DNS managed zone used by the
+// assertions in this file.
+type managedZone struct {
+	Name               string            `json:"name"`
+	DNSName            string            `json:"dnsName"`
+	Visibility         string            `json:"visibility"`
+	CloudLoggingConfig *dnsLoggingConfig `json:"cloudLoggingConfig"`
+	// PrivateVisibilityConfig lists the VPC networks a private zone is
+	// visible to.
+	PrivateVisibilityConfig *struct {
+		Networks []struct {
+			NetworkURL string `json:"networkUrl"`
+		} `json:"networks"`
+	} `json:"privateVisibilityConfig"`
+}
+
+type dnsLoggingConfig struct {
+	EnableLogging bool `json:"enableLogging"`
+}
+
+func describeManagedZone(t *testing.T, projectID, zoneName string) managedZone {
+	t.Helper()
+
+	var zone managedZone
+	if err := gcloudJSON(&zone, "dns", "managed-zones", "describe", zoneName, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe managed zone %s: %v", zoneName, err)
+	}
+	return zone
+}
+
+// checkDNSLogging returns an error if the zone does not log DNS queries.
+func checkDNSLogging(zone managedZone) error {
+	if zone.CloudLoggingConfig == nil {
+		return fmt.Errorf("managed zone %s has no cloudLoggingConfig", zone.Name)
+	}
+	if !zone.CloudLoggingConfig.EnableLogging {
+		return fmt.Errorf("managed zone %s has query logging disabled", zone.Name)
+	}
+	return nil
+}
+
+// AssertDNSLoggingEnabled fails the test if the managed zone does not have
+// DNS query logging enabled.
+func AssertDNSLoggingEnabled(t *testing.T, projectID, zoneName string) {
+	t.Helper()
+
+	zone := describeManagedZone(t, projectID, zoneName)
+	enabled := zone.CloudLoggingConfig != nil && zone.CloudLoggingConfig.EnableLogging
+	t.Logf("Managed zone %s query logging enabled: %t", zoneName, enabled)
+	if err := checkDNSLogging(zone); err != nil {
+		t.Error(err)
+	}
+}
+
+// privateNetworks returns the names of the networks a private zone is
+// visible to.
+func (z managedZone) privateNetworks() []string {
+	if z.PrivateVisibilityConfig == nil {
+		return nil
+	}
+	networks := make([]string, 0, len(z.PrivateVisibilityConfig.Networks))
+	for _, n := range z.PrivateVisibilityConfig.Networks {
+		networks = append(networks, lastSegment(n.NetworkURL))
+	}
+	return networks
+}
+
+// checkDNSZoneVisibility returns the differences between the zone's
+// visibility and expectVisibility and, for private zones, between the
+// attached networks and expectNetworks. Networks compare by name.
+func checkDNSZoneVisibility(zone managedZone, expectVisibility string, expectNetworks []string) []string {
+	if !strings.EqualFold(zone.Visibility, expectVisibility) {
+		return []string{fmt.Sprintf("visibility is %s, want %s", zone.Visibility, strings.ToLower(expectVisibility))}
+	}
+	if !strings.EqualFold(zone.Visibility, "private") {
+		return nil
+	}
+
+	want := make([]string, 0, len(expectNetworks))
+	for _, n := range expectNetworks {
+		want = append(want, lastSegment(n))
+	}
+	actual := zone.privateNetworks()
+
+	var problems []string
+	for _, n := range missingFrom(want, actual) {
+		problems = append(problems, fmt.Sprintf("network %s is not attached", n))
+	}
+	for _, n := range missingFrom(actual, want) {
+		problems = append(problems, fmt.Sprintf("unexpected network %s is attached", n))
+	}
+	return problems
+}
+
+// AssertDNSZoneVisibility fails the test unless the managed zone has
+// expectVisibility ("public" or "private") and, when private, is attached to
+// exactly expectNetworks.
+func AssertDNSZoneVisibility(t *testing.T, projectID, zoneName, expectVisibility string, expectNetworks []string) {
+	t.Helper()
+
+	zone := describeManagedZone(t, projectID, zoneName)
+	t.Logf("Managed zone %s visibility=%s networks=[%s]", zoneName, zone.Visibility, strings.Join(zone.privateNetworks(), ", "))
+	for _, p := range checkDNSZoneVisibility(zone, expectVisibility, expectNetworks) {
+		t.Errorf("Managed zone %s: %s", zoneName, p)
+	}
+}
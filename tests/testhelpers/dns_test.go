@@ -0,0 +1,55 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckDNSLogging(t *testing.T) {
+	cases := []struct {
+		name    string
+		zone    managedZone
+		wantErr bool
+	}{
+		{"enabled", managedZone{Name: "zone", CloudLoggingConfig: &dnsLoggingConfig{EnableLogging: true}}, false},
+		{"disabled", managedZone{Name: "zone", CloudLoggingConfig: &dnsLoggingConfig{}}, true},
+		{"missing config", managedZone{Name: "zone"}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkDNSLogging(tc.zone); (err != nil) != tc.wantErr {
+				t.Errorf("checkDNSLogging() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckDNSZoneVisibility(t *testing.T) {
+	const private = `{"name":"internal","visibility":"private","privateVisibilityConfig":{"networks":[
+		{"networkUrl":"https://www.googleapis.com/compute/v1/projects/p/global/networks/vpc-a"},
+		{"networkUrl":"https://www.googleapis.com/compute/v1/projects/p/global/networks/vpc-b"}]}}`
+	cases := []struct {
+		name         string
+		raw          string
+		visibility   string
+		networks     []string
+		wantProblems int
+	}{
+		{"private matches", private, "private", []string{"vpc-b", "projects/p/global/networks/vpc-a"}, 0},
+		{"private missing and extra", private, "private", []string{"vpc-a", "vpc-c"}, 2},
+		{"private expected public", private, "public", nil, 1},
+		{"public matches", `{"name":"example","visibility":"public"}`, "PUBLIC", nil, 0},
+		{"public expected private", `{"name":"example","visibility":"public"}`, "private", []string{"vpc-a"}, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var zone managedZone
+			if err := json.Unmarshal([]byte(tc.raw), &zone); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkDNSZoneVisibility(zone, tc.visibility, tc.networks); len(got) != tc.wantProblems {
+				t.Errorf("checkDNSZoneVisibility() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
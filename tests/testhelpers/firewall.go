@@ -0,0 +1,237 @@
+package testhelpers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// SensitivePorts are ports that must not be opened to every instance in a
+// network. AssertFirewallUsesTargetTags flags allow rules exposing them
+// without target tags or service accounts.
+var SensitivePorts = []int{22, 3389, 3306, 5432, 6379, 1433, 27017}
+
+// firewallRule mirrors the fields of a VPC firewall rule used by the
+// assertions in this file.
+type firewallRule struct {
+	Name                  string          `json:"name"`
+	Network               string          `json:"network"`
+	Direction             string          `json:"direction"`
+	Priority              int             `json:"priority"`
+	Disabled              bool            `json:"disabled"`
+	SourceRanges          []string        `json:"sourceRanges"`
+	TargetTags            []string        `json:"targetTags"`
+	TargetServiceAccounts []string        `json:"targetServiceAccounts"`
+	Allowed               []firewallPorts `json:"allowed"`
+	Denied                []firewallPorts `json:"denied"`
+	LogConfig             struct {
+		Enable bool `json:"enable"`
+	} `json:"logConfig"`
+}
+
+type firewallPorts struct {
+	IPProtocol string   `json:"IPProtocol"`
+	Ports      []string `json:"ports"`
+}
+
+// listFirewallRules returns the firewall rules attached to network.
+func listFirewallRules(t *testing.T, projectID, network string) []firewallRule {
+	t.Helper()
+
+	var all []firewallRule
+	if err := gcloudJSON(&all, "compute", "firewall-rules", "list", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list firewall rules in project %s: %v", projectID, err)
+	}
+
+	var rules []firewallRule
+	for _, r := range all {
+		if lastSegment(r.Network) == network {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// portSpecCovers reports whether a firewall port spec ("22" or "20-25")
+// includes port.
+func portSpecCovers(spec string, port int) bool {
+	lo, hi, isRange := strings.Cut(spec, "-")
+	start, err := strconv.Atoi(lo)
+	if err != nil {
+		return false
+	}
+	if !isRange {
+		return start == port
+	}
+	end, err := strconv.Atoi(hi)
+	if err != nil {
+		return false
+	}
+	return start <= port && port <= end
+}
+
+// exposedPorts returns the sensitive ports opened by an allow rule. A TCP,
+// UDP or "all" entry without ports opens every port.
+func exposedPorts(rule firewallRule, sensitive []int) []int {
+	var exposed []int
+	for _, port := range sensitive {
+		for _, a := range rule.Allowed {
+			proto := strings.ToLower(a.IPProtocol)
+			if proto != "tcp" && proto != "udp" && proto != "all" {
+				continue
+			}
+			covered := len(a.Ports) == 0
+			for _, spec := range a.Ports {
+				if portSpecCovers(spec, port) {
+					covered = true
+				}
+			}
+			if covered {
+				exposed = append(exposed, port)
+				break
+			}
+		}
+	}
+	return exposed
+}
+
+// untargetedSensitiveRules returns a description of every enabled ingress
+// allow rule that applies to all instances and opens a sensitive port.
+func untargetedSensitiveRules(rules []firewallRule, sensitive []int) []string {
+	var offending []string
+	for _, r := range rules {
+		if r.Disabled || len(r.Allowed) == 0 || (r.Direction != "" && r.Direction != "INGRESS") {
+			continue
+		}
+		if len(r.TargetTags) > 0 || len(r.TargetServiceAccounts) > 0 {
+			continue
+		}
+		if ports := exposedPorts(r, sensitive); len(ports) > 0 {
+			offending = append(offending, fmt.Sprintf("%s (ports %s)", r.Name, strings.Trim(fmt.Sprint(ports), "[]")))
+		}
+	}
+	sort.Strings(offending)
+	return offending
+}
+
+// AssertFirewallUsesTargetTags fails the test if any allow rule in network
+// opens a SensitivePorts port to all instances instead of scoping it with
+// target tags or target service accounts.
+func AssertFirewallUsesTargetTags(t *testing.T, projectID, network string) {
+	t.Helper()
+
+	offending := untargetedSensitiveRules(listFirewallRules(t, projectID, network), SensitivePorts)
+	if len(offending) > 0 {
+		t.Errorf("Network %s has allow rules targeting all instances on sensitive ports: %s",
+			network, strings.Join(offending, ", "))
+	}
+}
+
+// rulesWithoutLogging returns the names of enabled rules that do not log.
+// When denyOnly is set only deny rules are considered.
+func rulesWithoutLogging(rules []firewallRule, denyOnly bool) []string {
+	var offending []string
+	for _, r := range rules {
+		if r.Disabled || (denyOnly && len(r.Denied) == 0) {
+			continue
+		}
+		if !r.LogConfig.Enable {
+			offending = append(offending, r.Name)
+		}
+	}
+	sort.Strings(offending)
+	return offending
+}
+
+// AssertFirewallLogging fails the test listing firewall rules in network
+// without logging. With requireOnDenies set only deny rules must log;
+// otherwise every enabled rule must.
+func AssertFirewallLogging(t *testing.T, projectID, network string, requireOnDenies bool) {
+	t.Helper()
+
+	scope := "rules"
+	if requireOnDenies {
+		scope = "deny rules"
+	}
+	if offending := rulesWithoutLogging(listFirewallRules(t, projectID, network), requireOnDenies); len(offending) > 0 {
+		t.Errorf("Network %s has %s without logging: %s", network, scope, strings.Join(offending, ", "))
+	}
+}
+
+// RuleExpectation names a firewall rule for AssertFirewallPriorityOrder.
+// Action, when set to "allow" or "deny", must also match the rule.
+type RuleExpectation struct {
+	Name   string
+	Action string
+}
+
+func (r firewallRule) action() string {
+	if len(r.Denied) > 0 {
+		return "deny"
+	}
+	return "allow"
+}
+
+// direction returns the rule's direction; the API defaults it to INGRESS.
+func (r firewallRule) direction() string {
+	if r.Direction == "" {
+		return "INGRESS"
+	}
+	return strings.ToUpper(r.Direction)
+}
+
+// checkFirewallPriorityOrder returns the violations of the expected
+// evaluation order: each expected rule must exist, have the expected action
+// and be evaluated before the next expected rule of the same direction. That
+// needs a lower priority number, or an equal one if the earlier rule denies,
+// since GCP applies deny rules first on a tie.
+func checkFirewallPriorityOrder(rules []firewallRule, expected []RuleExpectation) []string {
+	byName := make(map[string]firewallRule, len(rules))
+	for _, r := range rules {
+		byName[r.Name] = r
+	}
+
+	var violations []string
+	prev := make(map[string]firewallRule)
+	for _, e := range expected {
+		r, ok := byName[e.Name]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("rule %s not found", e.Name))
+			prev = make(map[string]firewallRule)
+			continue
+		}
+		if e.Action != "" && !strings.EqualFold(e.Action, r.action()) {
+			violations = append(violations, fmt.Sprintf("rule %s is %s, want %s", r.Name, r.action(), strings.ToLower(e.Action)))
+		}
+		if p, ok := prev[r.direction()]; ok {
+			if p.Priority > r.Priority || (p.Priority == r.Priority && p.action() != "deny") {
+				violations = append(violations, fmt.Sprintf("rule %s (priority %d) must be evaluated before %s (priority %d)",
+					p.Name, p.Priority, r.Name, r.Priority))
+			}
+		}
+		prev[r.direction()] = r
+	}
+	return violations
+}
+
+// AssertFirewallPriorityOrder fails the test unless the named rules in
+// network are evaluated in the order given, i.e. each has a lower priority
+// number than the next rule of the same direction, or an equal one if it
+// denies.
+func AssertFirewallPriorityOrder(t *testing.T, projectID, network string, rules []RuleExpectation) {
+	t.Helper()
+
+	actual := listFirewallRules(t, projectID, network)
+	for _, r := range actual {
+		for _, e := range rules {
+			if r.Name == e.Name {
+				t.Logf("Network %s: firewall rule %s %s priority %d", network, r.Name, r.action(), r.Priority)
+			}
+		}
+	}
+	for _, v := range checkFirewallPriorityOrder(actual, rules) {
+		t.Errorf("Network %s: %s", network, v)
+	}
+}
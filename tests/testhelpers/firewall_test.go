@@ -0,0 +1,100 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPortSpecCovers(t *testing.T) {
+	cases := []struct {
+		spec string
+		port int
+		want bool
+	}{
+		{"22", 22, true},
+		{"80", 22, false},
+		{"20-25", 22, true},
+		{"1000-2000", 22, false},
+		{"bogus", 22, false},
+	}
+	for _, tc := range cases {
+		if got := portSpecCovers(tc.spec, tc.port); got != tc.want {
+			t.Errorf("portSpecCovers(%q, %d) = %t, want %t", tc.spec, tc.port, got, tc.want)
+		}
+	}
+}
+
+func TestUntargetedSensitiveRules(t *testing.T) {
+	rules := []firewallRule{
+		{Name: "allow-ssh-all", Direction: "INGRESS", Allowed: []firewallPorts{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+		{Name: "allow-ssh-tagged", Direction: "INGRESS", TargetTags: []string{"bastion"},
+			Allowed: []firewallPorts{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+		{Name: "allow-db-sa", Direction: "INGRESS", TargetServiceAccounts: []string{"db@p.iam.gserviceaccount.com"},
+			Allowed: []firewallPorts{{IPProtocol: "tcp", Ports: []string{"5432"}}}},
+		{Name: "allow-https", Direction: "INGRESS", Allowed: []firewallPorts{{IPProtocol: "tcp", Ports: []string{"443"}}}},
+		{Name: "allow-internal", Direction: "INGRESS", Allowed: []firewallPorts{{IPProtocol: "all"}}},
+		{Name: "allow-icmp", Direction: "INGRESS", Allowed: []firewallPorts{{IPProtocol: "icmp"}}},
+		{Name: "disabled-rdp", Direction: "INGRESS", Disabled: true, Allowed: []firewallPorts{{IPProtocol: "tcp", Ports: []string{"3389"}}}},
+		{Name: "deny-ssh", Direction: "INGRESS", Denied: []firewallPorts{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+	}
+
+	got := untargetedSensitiveRules(rules, []int{22, 3389, 5432})
+	want := []string{"allow-internal (ports 22 3389 5432)", "allow-ssh-all (ports 22)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("untargetedSensitiveRules() = %v, want %v", got, want)
+	}
+}
+
+func TestRulesWithoutLogging(t *testing.T) {
+	var rules []firewallRule
+	raw := `[
+		{"name":"deny-all-logged","denied":[{"IPProtocol":"all"}],"logConfig":{"enable":true}},
+		{"name":"deny-ssh-unlogged","denied":[{"IPProtocol":"tcp","ports":["22"]}]},
+		{"name":"allow-https-unlogged","allowed":[{"IPProtocol":"tcp","ports":["443"]}]},
+		{"name":"deny-disabled","disabled":true,"denied":[{"IPProtocol":"all"}]}]`
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rulesWithoutLogging(rules, true); !reflect.DeepEqual(got, []string{"deny-ssh-unlogged"}) {
+		t.Errorf("deny rules: got %v", got)
+	}
+	if got := rulesWithoutLogging(rules, false); !reflect.DeepEqual(got, []string{"allow-https-unlogged", "deny-ssh-unlogged"}) {
+		t.Errorf("all rules: got %v", got)
+	}
+}
+
+func TestCheckFirewallPriorityOrder(t *testing.T) {
+	var rules []firewallRule
+	raw := `[
+		{"name":"deny-bad-ips","priority":100,"denied":[{"IPProtocol":"all"}]},
+		{"name":"allow-lb","priority":1000,"allowed":[{"IPProtocol":"tcp","ports":["80","443"]}]},
+		{"name":"deny-ssh","priority":1000,"denied":[{"IPProtocol":"tcp","ports":["22"]}]},
+		{"name":"allow-ssh-iap","priority":2000,"allowed":[{"IPProtocol":"tcp","ports":["22"]}]},
+		{"name":"allow-egress-google","direction":"EGRESS","priority":50,"allowed":[{"IPProtocol":"tcp","ports":["443"]}]}]`
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		expected []RuleExpectation
+		want     int
+	}{
+		{"in order", []RuleExpectation{{"deny-bad-ips", "deny"}, {"allow-lb", "allow"}, {"allow-ssh-iap", ""}}, 0},
+		{"reversed", []RuleExpectation{{"allow-ssh-iap", ""}, {"deny-ssh", ""}}, 1},
+		{"equal priority allow first", []RuleExpectation{{"allow-lb", ""}, {"deny-ssh", ""}}, 1},
+		{"equal priority deny first", []RuleExpectation{{"deny-ssh", "deny"}, {"allow-lb", "allow"}}, 0},
+		{"other direction not compared", []RuleExpectation{{"deny-bad-ips", ""}, {"allow-egress-google", ""}, {"allow-lb", ""}}, 0},
+		{"wrong action", []RuleExpectation{{"deny-bad-ips", "allow"}}, 1},
+		{"missing rule", []RuleExpectation{{"deny-bad-ips", ""}, {"nope", ""}, {"allow-lb", ""}}, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkFirewallPriorityOrder(rules, tc.expected); len(got) != tc.want {
+				t.Errorf("checkFirewallPriorityOrder() = %v, want %d violation(s)", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package testhelpers provides assertion helpers for Terratest-based
+// integration tests of the GCP infrastructure modules.
+//
+// Each Assert* helper fetches live resource state through the gcloud (or bq)
+// CLI and hands it to a pure check function, so the policy logic can be
+// unit-tested without touching a real project.
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// commandRunner executes a CLI and returns its stdout. Unit tests replace it
+// to serve canned JSON.
+var commandRunner = func(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// runJSON runs a CLI command and decodes its stdout into v.
+func runJSON(v interface{}, name string, args ...string) error {
+	out, err := commandRunner(name, args...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("decoding %s %s output: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// gcloudJSON runs a gcloud command with JSON output and decodes it into v.
+func gcloudJSON(v interface{}, args ...string) error {
+	return runJSON(v, "gcloud", append(args, "--format=json")...)
+}
+
+// bqJSON runs a bq command with JSON output and decodes it into v.
+func bqJSON(v interface{}, args ...string) error {
+	return runJSON(v, "bq", append([]string{"--format=json"}, args...)...)
+}
+
+// lastSegment returns the final path component of a GCP resource URL or
+// relative name, e.g. the zone name from a zone self link.
+func lastSegment(resource string) string {
+	if i := strings.LastIndex(resource, "/"); i >= 0 {
+		return resource[i+1:]
+	}
+	return resource
+}
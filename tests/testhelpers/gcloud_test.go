@@ -0,0 +1,26 @@
+package testhelpers
+
+import "testing"
+
+// stubGcloud makes commandRunner return output for every call during the test.
+func stubGcloud(t *testing.T, output string) {
+	t.Helper()
+	orig := commandRunner
+	commandRunner = func(string, ...string) ([]byte, error) {
+		return []byte(output), nil
+	}
+	t.Cleanup(func() { commandRunner = orig })
+}
+
+func TestLastSegment(t *testing.T) {
+	cases := map[string]string{
+		"https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a": "us-central1-a",
+		"projects/p/regions/us-central1":                                       "us-central1",
+		"plain":                                                                "plain",
+	}
+	for in, want := range cases {
+		if got := lastSegment(in); got != want {
+			t.Errorf("lastSegment(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
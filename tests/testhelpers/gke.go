@@ -0,0 +1,284 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// gkeCluster mirrors the fields of a GKE cluster used by the assertions in
+// this file.
+type gkeCluster struct {
+	Name                   string `json:"name"`
+	WorkloadIdentityConfig *struct {
+		WorkloadPool string `json:"workloadPool"`
+	} `json:"workloadIdentityConfig"`
+	NodePools                []gkeNodePool      `json:"nodePools"`
+	DefaultMaxPodsConstraint *maxPodsConstraint `json:"defaultMaxPodsConstraint"`
+	BinaryAuthorization      *struct {
+		Enabled        bool   `json:"enabled"`
+		EvaluationMode string `json:"evaluationMode"`
+	} `json:"binaryAuthorization"`
+	ReleaseChannel *struct {
+		Channel string `json:"channel"`
+	} `json:"releaseChannel"`
+}
+
+// maxPodsConstraint holds an int64, which the API encodes as a JSON string.
+type maxPodsConstraint struct {
+	MaxPodsPerNode int64 `json:"maxPodsPerNode,string"`
+}
+
+// gkeNodePool mirrors the fields of a GKE node pool used by the assertions
+// in this file.
+type gkeNodePool struct {
+	Name              string             `json:"name"`
+	MaxPodsConstraint *maxPodsConstraint `json:"maxPodsConstraint"`
+	Autoscaling       *struct {
+		Enabled           bool `json:"enabled"`
+		MinNodeCount      int  `json:"minNodeCount"`
+		MaxNodeCount      int  `json:"maxNodeCount"`
+		TotalMinNodeCount int  `json:"totalMinNodeCount"`
+		TotalMaxNodeCount int  `json:"totalMaxNodeCount"`
+	} `json:"autoscaling"`
+	Config struct {
+		WorkloadMetadataConfig *struct {
+			Mode string `json:"mode"`
+		} `json:"workloadMetadataConfig"`
+	} `json:"config"`
+}
+
+func describeCluster(t *testing.T, projectID, location, cluster string) gkeCluster {
+	t.Helper()
+
+	var c gkeCluster
+	if err := gcloudJSON(&c, "container", "clusters", "describe", cluster,
+		"--location", location, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe GKE cluster %s: %v", cluster, err)
+	}
+	return c
+}
+
+func (c gkeCluster) workloadPool() string {
+	if c.WorkloadIdentityConfig == nil {
+		return ""
+	}
+	return c.WorkloadIdentityConfig.WorkloadPool
+}
+
+func (p gkeNodePool) metadataMode() string {
+	if p.Config.WorkloadMetadataConfig == nil {
+		return ""
+	}
+	return p.Config.WorkloadMetadataConfig.Mode
+}
+
+// checkClusterWorkloadIdentity returns the workload identity problems for the
+// cluster: the pool must be PROJECT.svc.id.goog and every node pool must
+// serve metadata through the GKE metadata server, which conceals the node's
+// own credentials from workloads.
+func checkClusterWorkloadIdentity(c gkeCluster, projectID string) []string {
+	var problems []string
+	if want := projectID + ".svc.id.goog"; c.workloadPool() != want {
+		problems = append(problems, fmt.Sprintf("workload pool is %q, want %q", c.workloadPool(), want))
+	}
+	for _, p := range c.NodePools {
+		if p.metadataMode() != "GKE_METADATA" {
+			problems = append(problems, fmt.Sprintf("node pool %s metadata mode is %q, want GKE_METADATA", p.Name, p.metadataMode()))
+		}
+	}
+	return problems
+}
+
+// AssertClusterWorkloadIdentity fails the test unless workload identity is
+// enabled on the cluster with the project's pool and every node pool uses
+// the GKE metadata server.
+func AssertClusterWorkloadIdentity(t *testing.T, projectID, location, cluster string) {
+	t.Helper()
+
+	c := describeCluster(t, projectID, location, cluster)
+	t.Logf("GKE cluster %s workload pool: %q", cluster, c.workloadPool())
+	for _, p := range c.NodePools {
+		t.Logf("GKE cluster %s node pool %s metadata mode: %q", cluster, p.Name, p.metadataMode())
+	}
+	for _, p := range checkClusterWorkloadIdentity(c, projectID) {
+		t.Errorf("GKE cluster %s: %s", cluster, p)
+	}
+}
+
+// nodePool returns the named node pool of the cluster.
+func (c gkeCluster) nodePool(name string) (gkeNodePool, bool) {
+	for _, p := range c.NodePools {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return gkeNodePool{}, false
+}
+
+// maxPodsPerNode returns the node pool's max pods per node, falling back to
+// the cluster default. Zero means neither is set.
+func (c gkeCluster) maxPodsPerNode(pool gkeNodePool) int64 {
+	if pool.MaxPodsConstraint != nil {
+		return pool.MaxPodsConstraint.MaxPodsPerNode
+	}
+	if c.DefaultMaxPodsConstraint != nil {
+		return c.DefaultMaxPodsConstraint.MaxPodsPerNode
+	}
+	return 0
+}
+
+// checkMaxPodsPerNode returns an error if the node pool's max pods per node
+// is unset or above maxAllowed.
+func checkMaxPodsPerNode(c gkeCluster, nodePool string, maxAllowed int) error {
+	pool, ok := c.nodePool(nodePool)
+	if !ok {
+		return fmt.Errorf("node pool %s not found", nodePool)
+	}
+	actual := c.maxPodsPerNode(pool)
+	if actual == 0 {
+		return fmt.Errorf("node pool %s has no max pods per node constraint", nodePool)
+	}
+	if actual > int64(maxAllowed) {
+		return fmt.Errorf("node pool %s allows %d pods per node, want at most %d", nodePool, actual, maxAllowed)
+	}
+	return nil
+}
+
+// AssertMaxPodsPerNode fails the test if the node pool allows more than
+// maxAllowed pods per node, which would reserve oversized pod ranges.
+func AssertMaxPodsPerNode(t *testing.T, projectID, location, cluster, nodePool string, maxAllowed int) {
+	t.Helper()
+
+	c := describeCluster(t, projectID, location, cluster)
+	if pool, ok := c.nodePool(nodePool); ok {
+		t.Logf("GKE cluster %s node pool %s max pods per node: %d", cluster, nodePool, c.maxPodsPerNode(pool))
+	}
+	if err := checkMaxPodsPerNode(c, nodePool, maxAllowed); err != nil {
+		t.Errorf("GKE cluster %s: %v", cluster, err)
+	}
+}
+
+// binaryAuthorizationMode returns the cluster's Binary Authorization
+// evaluation mode, mapping the legacy enabled flag to
+// PROJECT_SINGLETON_POLICY_ENFORCE.
+func (c gkeCluster) binaryAuthorizationMode() string {
+	ba := c.BinaryAuthorization
+	switch {
+	case ba == nil:
+		return "DISABLED"
+	case ba.EvaluationMode != "" && ba.EvaluationMode != "EVALUATION_MODE_UNSPECIFIED":
+		return ba.EvaluationMode
+	case ba.Enabled:
+		return "PROJECT_SINGLETON_POLICY_ENFORCE"
+	}
+	return "DISABLED"
+}
+
+// checkBinaryAuthorization returns an error if Binary Authorization is
+// disabled or, when requireEnforced is set, not enforcing the project policy.
+func checkBinaryAuthorization(c gkeCluster, requireEnforced bool) error {
+	mode := c.binaryAuthorizationMode()
+	if mode == "DISABLED" {
+		return fmt.Errorf("binary authorization is disabled")
+	}
+	if requireEnforced && !strings.HasSuffix(mode, "PROJECT_SINGLETON_POLICY_ENFORCE") {
+		return fmt.Errorf("binary authorization mode is %s, want project policy enforcement", mode)
+	}
+	return nil
+}
+
+// AssertBinaryAuthorization fails the test if Binary Authorization is
+// disabled on the cluster, or not enforcing when requireEnforced is set.
+func AssertBinaryAuthorization(t *testing.T, projectID, location, cluster string, requireEnforced bool) {
+	t.Helper()
+
+	c := describeCluster(t, projectID, location, cluster)
+	t.Logf("GKE cluster %s binary authorization mode: %s", cluster, c.binaryAuthorizationMode())
+	if err := checkBinaryAuthorization(c, requireEnforced); err != nil {
+		t.Errorf("GKE cluster %s: %v", cluster, err)
+	}
+}
+
+// releaseChannel returns the cluster's release channel, or UNSPECIFIED when
+// it is not enrolled in one.
+func (c gkeCluster) releaseChannel() string {
+	if c.ReleaseChannel == nil || c.ReleaseChannel.Channel == "" {
+		return "UNSPECIFIED"
+	}
+	return c.ReleaseChannel.Channel
+}
+
+// checkReleaseChannel returns an error if the cluster's release channel is
+// not expectedChannel.
+func checkReleaseChannel(c gkeCluster, expectedChannel string) error {
+	if actual := c.releaseChannel(); !strings.EqualFold(actual, expectedChannel) {
+		return fmt.Errorf("release channel is %s, want %s", actual, strings.ToUpper(expectedChannel))
+	}
+	return nil
+}
+
+// AssertReleaseChannel fails the test unless the cluster is enrolled in
+// expectedChannel (RAPID, REGULAR or STABLE).
+func AssertReleaseChannel(t *testing.T, projectID, location, cluster, expectedChannel string) {
+	t.Helper()
+
+	c := describeCluster(t, projectID, location, cluster)
+	t.Logf("GKE cluster %s release channel: %s", cluster, c.releaseChannel())
+	if err := checkReleaseChannel(c, expectedChannel); err != nil {
+		t.Errorf("GKE cluster %s: %v", cluster, err)
+	}
+}
+
+// autoscalingBounds returns the node pool's autoscaling bounds and whether
+// they are per zone. Pools configured with total limits report those.
+func (p gkeNodePool) autoscalingBounds() (lo, hi int, perZone bool) {
+	a := p.Autoscaling
+	if a.TotalMinNodeCount != 0 || a.TotalMaxNodeCount != 0 {
+		return a.TotalMinNodeCount, a.TotalMaxNodeCount, false
+	}
+	return a.MinNodeCount, a.MaxNodeCount, true
+}
+
+// checkNodePoolAutoscaling returns the reasons the node pool does not
+// autoscale between minNodes and maxNodes.
+func checkNodePoolAutoscaling(c gkeCluster, nodePool string, minNodes, maxNodes int) []string {
+	pool, ok := c.nodePool(nodePool)
+	if !ok {
+		return []string{fmt.Sprintf("node pool %s not found", nodePool)}
+	}
+	if pool.Autoscaling == nil || !pool.Autoscaling.Enabled {
+		return []string{fmt.Sprintf("node pool %s has autoscaling disabled", nodePool)}
+	}
+
+	lo, hi, _ := pool.autoscalingBounds()
+	var problems []string
+	if lo != minNodes {
+		problems = append(problems, fmt.Sprintf("node pool %s min nodes is %d, want %d", nodePool, lo, minNodes))
+	}
+	if hi != maxNodes {
+		problems = append(problems, fmt.Sprintf("node pool %s max nodes is %d, want %d", nodePool, hi, maxNodes))
+	}
+	return problems
+}
+
+// AssertNodePoolAutoscaling fails the test unless autoscaling is enabled on
+// the node pool with exactly minNodes and maxNodes as its bounds. Bounds are
+// per zone unless the pool sets total limits.
+func AssertNodePoolAutoscaling(t *testing.T, projectID, location, cluster, nodePool string, minNodes, maxNodes int) {
+	t.Helper()
+
+	c := describeCluster(t, projectID, location, cluster)
+	if pool, ok := c.nodePool(nodePool); ok && pool.Autoscaling != nil {
+		lo, hi, perZone := pool.autoscalingBounds()
+		scope := "total"
+		if perZone {
+			scope = "per zone"
+		}
+		t.Logf("GKE cluster %s node pool %s autoscaling enabled=%t min=%d max=%d (%s)",
+			cluster, nodePool, pool.Autoscaling.Enabled, lo, hi, scope)
+	}
+	for _, p := range checkNodePoolAutoscaling(c, nodePool, minNodes, maxNodes) {
+		t.Errorf("GKE cluster %s: %s", cluster, p)
+	}
+}
@@ -0,0 +1,138 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeCluster(t *testing.T, raw string) gkeCluster {
+	t.Helper()
+	var c gkeCluster
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCheckClusterWorkloadIdentity(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		wantProblems int
+	}{
+		{"enabled", `{"workloadIdentityConfig":{"workloadPool":"proj.svc.id.goog"},
+			"nodePools":[{"name":"default","config":{"workloadMetadataConfig":{"mode":"GKE_METADATA"}}}]}`, 0},
+		{"wrong pool", `{"workloadIdentityConfig":{"workloadPool":"other.svc.id.goog"},
+			"nodePools":[{"name":"default","config":{"workloadMetadataConfig":{"mode":"GKE_METADATA"}}}]}`, 1},
+		{"node pool exposes metadata", `{"workloadIdentityConfig":{"workloadPool":"proj.svc.id.goog"},
+			"nodePools":[{"name":"default","config":{"workloadMetadataConfig":{"mode":"GCE_METADATA"}}},{"name":"batch","config":{}}]}`, 2},
+		{"disabled", `{"nodePools":[{"name":"default","config":{}}]}`, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkClusterWorkloadIdentity(decodeCluster(t, tc.raw), "proj"); len(got) != tc.wantProblems {
+				t.Errorf("checkClusterWorkloadIdentity() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckMaxPodsPerNode(t *testing.T) {
+	c := decodeCluster(t, `{"defaultMaxPodsConstraint":{"maxPodsPerNode":"110"},"nodePools":[
+		{"name":"small","maxPodsConstraint":{"maxPodsPerNode":"32"}},
+		{"name":"inherits"}]}`)
+
+	cases := []struct {
+		pool       string
+		maxAllowed int
+		wantErr    bool
+	}{
+		{"small", 32, false},
+		{"small", 16, true},
+		{"inherits", 110, false},
+		{"inherits", 64, true},
+		{"missing", 64, true},
+	}
+	for _, tc := range cases {
+		if err := checkMaxPodsPerNode(c, tc.pool, tc.maxAllowed); (err != nil) != tc.wantErr {
+			t.Errorf("checkMaxPodsPerNode(%s, %d) error = %v, wantErr %t", tc.pool, tc.maxAllowed, err, tc.wantErr)
+		}
+	}
+
+	if err := checkMaxPodsPerNode(decodeCluster(t, `{"nodePools":[{"name":"p"}]}`), "p", 110); err == nil {
+		t.Error("expected an error when no constraint is set")
+	}
+}
+
+func TestCheckBinaryAuthorization(t *testing.T) {
+	cases := []struct {
+		name            string
+		raw             string
+		requireEnforced bool
+		wantErr         bool
+	}{
+		{"enforced", `{"binaryAuthorization":{"evaluationMode":"PROJECT_SINGLETON_POLICY_ENFORCE"}}`, true, false},
+		{"bindings and enforced", `{"binaryAuthorization":{"evaluationMode":"POLICY_BINDINGS_AND_PROJECT_SINGLETON_POLICY_ENFORCE"}}`, true, false},
+		{"legacy enabled", `{"binaryAuthorization":{"enabled":true}}`, true, false},
+		{"bindings only", `{"binaryAuthorization":{"evaluationMode":"POLICY_BINDINGS"}}`, true, true},
+		{"bindings only not required", `{"binaryAuthorization":{"evaluationMode":"POLICY_BINDINGS"}}`, false, false},
+		{"disabled", `{"binaryAuthorization":{"evaluationMode":"DISABLED"}}`, false, true},
+		{"absent", `{}`, false, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkBinaryAuthorization(decodeCluster(t, tc.raw), tc.requireEnforced); (err != nil) != tc.wantErr {
+				t.Errorf("checkBinaryAuthorization() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseChannel(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected string
+		wantErr  bool
+	}{
+		{"regular", `{"releaseChannel":{"channel":"REGULAR"}}`, "REGULAR", false},
+		{"case-insensitive", `{"releaseChannel":{"channel":"STABLE"}}`, "stable", false},
+		{"rapid vs stable", `{"releaseChannel":{"channel":"RAPID"}}`, "STABLE", true},
+		{"not enrolled", `{}`, "REGULAR", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkReleaseChannel(decodeCluster(t, tc.raw), tc.expected); (err != nil) != tc.wantErr {
+				t.Errorf("checkReleaseChannel() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckNodePoolAutoscaling(t *testing.T) {
+	c := decodeCluster(t, `{"nodePools":[
+		{"name":"default","autoscaling":{"enabled":true,"minNodeCount":1,"maxNodeCount":5}},
+		{"name":"batch","autoscaling":{"enabled":true,"totalMinNodeCount":0,"totalMaxNodeCount":10,"locationPolicy":"ANY"}},
+		{"name":"fixed","initialNodeCount":3},
+		{"name":"off","autoscaling":{}}]}`)
+	cases := []struct {
+		name         string
+		pool         string
+		min, max     int
+		wantProblems int
+	}{
+		{"per zone matches", "default", 1, 5, 0},
+		{"per zone differs", "default", 2, 3, 2},
+		{"total limits", "batch", 0, 10, 0},
+		{"no autoscaling block", "fixed", 1, 3, 1},
+		{"disabled", "off", 1, 3, 1},
+		{"missing pool", "gpu", 1, 3, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkNodePoolAutoscaling(c, tc.pool, tc.min, tc.max); len(got) != tc.wantProblems {
+				t.Errorf("checkNodePoolAutoscaling() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
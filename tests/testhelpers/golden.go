@@ -0,0 +1,151 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// updateGoldenEnv makes AssertOutputsMatchGolden rewrite golden files from
+// the current outputs instead of comparing when set to a true value:
+//
+//	TEST_UPDATE_GOLDEN=1 go test ./... -run TestVPC
+const updateGoldenEnv = "TEST_UPDATE_GOLDEN"
+
+// updateGolden reports whether TEST_UPDATE_GOLDEN is set to a true value.
+func updateGolden() bool {
+	update, _ := strconv.ParseBool(os.Getenv(updateGoldenEnv))
+	return update
+}
+
+// redactedOutput replaces the value of sensitive outputs in golden files.
+const redactedOutput = "<sensitive>"
+
+// goldenOutputs decodes `terraform output -json` (every output, keyed by
+// name) into name -> value, redacting sensitive outputs.
+func goldenOutputs(rawOutputs string) (map[string]interface{}, error) {
+	var outputs map[string]struct {
+		Sensitive bool        `json:"sensitive"`
+		Value     interface{} `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(rawOutputs), &outputs); err != nil {
+		return nil, fmt.Errorf("decoding terraform outputs: %w", err)
+	}
+	values := make(map[string]interface{}, len(outputs))
+	for name, o := range outputs {
+		if o.Sensitive {
+			values[name] = redactedOutput
+		} else {
+			values[name] = o.Value
+		}
+	}
+	return values, nil
+}
+
+// marshalGolden renders outputs as the golden file content. Map keys are
+// sorted, so the file is stable across runs.
+func marshalGolden(outputs map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(outputs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// diffGolden returns the outputs that were added, removed or changed
+// relative to the golden file content.
+func diffGolden(golden []byte, actual map[string]interface{}) ([]string, error) {
+	var want map[string]interface{}
+	if err := json.Unmarshal(golden, &want); err != nil {
+		return nil, fmt.Errorf("decoding golden file: %w", err)
+	}
+	got, err := normalizeJSON(actual)
+	if err != nil {
+		return nil, err
+	}
+	gotMap, _ := got.(map[string]interface{})
+
+	var diffs []string
+	for _, name := range missingFrom(keysOf(want), keysOf(gotMap)) {
+		diffs = append(diffs, fmt.Sprintf("output %q is missing", name))
+	}
+	for _, name := range missingFrom(keysOf(gotMap), keysOf(want)) {
+		diffs = append(diffs, fmt.Sprintf("unexpected output %q", name))
+	}
+	names := keysOf(want)
+	sort.Strings(names)
+	for _, name := range names {
+		a, ok := gotMap[name]
+		if ok && !reflect.DeepEqual(a, want[name]) {
+			actualJSON, _ := json.Marshal(a)
+			wantJSON, _ := json.Marshal(want[name])
+			diffs = append(diffs, fmt.Sprintf("output %q = %s, golden %s", name, actualJSON, wantJSON))
+		}
+	}
+	return diffs, nil
+}
+
+func keysOf(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// AssertOutputsMatchGolden compares every terraform output for opts with
+// the JSON golden file at goldenPath, failing for each added, removed or
+// changed output. Sensitive outputs are redacted before comparison. Run the
+// tests with TEST_UPDATE_GOLDEN=1 to write the golden file from the current
+// outputs.
+func AssertOutputsMatchGolden(t *testing.T, opts *terraform.Options, goldenPath string) {
+	t.Helper()
+
+	// An empty name reads all outputs.
+	raw, err := outputReader(t, opts, "")
+	if err != nil {
+		t.Fatalf("Failed to read outputs: %v", err)
+	}
+	actual, err := goldenOutputs(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updateGolden() {
+		content, err := marshalGolden(actual)
+		if err != nil {
+			t.Fatalf("Failed to encode outputs: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(goldenPath), err)
+		}
+		if err := os.WriteFile(goldenPath, content, 0o644); err != nil {
+			t.Fatalf("Failed to write golden file %s: %v", goldenPath, err)
+		}
+		t.Logf("Updated golden file %s with %d output(s)", goldenPath, len(actual))
+		return
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Failed to read golden file %s (run with TEST_UPDATE_GOLDEN=1 to create it): %v", goldenPath, err)
+	}
+	diffs, err := diffGolden(golden, actual)
+	if err != nil {
+		t.Fatalf("Golden file %s: %v", goldenPath, err)
+	}
+	for _, d := range diffs {
+		t.Errorf("Outputs differ from %s: %s", goldenPath, d)
+	}
+}
@@ -0,0 +1,78 @@
+package testhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+const sampleOutputsJSON = `{
+  "network_name": {"sensitive": false, "type": "string", "value": "test-vpc"},
+  "subnet_cidrs": {"sensitive": false, "type": ["list", "string"], "value": ["10.0.0.0/24", "10.0.1.0/24"]},
+  "db_password": {"sensitive": true, "type": "string", "value": "hunter2"}
+}`
+
+func TestGoldenOutputsRedactsSensitive(t *testing.T) {
+	got, err := goldenOutputs(sampleOutputsJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["db_password"] != redactedOutput {
+		t.Errorf("db_password = %v, want redacted", got["db_password"])
+	}
+	if got["network_name"] != "test-vpc" {
+		t.Errorf("network_name = %v, want test-vpc", got["network_name"])
+	}
+}
+
+func TestDiffGolden(t *testing.T) {
+	golden := []byte(`{"network_name":"test-vpc","routing_mode":"GLOBAL","subnet_cidrs":["10.0.0.0/24"]}`)
+	actual := map[string]interface{}{
+		"network_name": "test-vpc",
+		"subnet_cidrs": []string{"10.0.0.0/24", "10.0.1.0/24"},
+		"db_password":  redactedOutput,
+	}
+	got, err := diffGolden(golden, actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`output "routing_mode" is missing`,
+		`unexpected output "db_password"`,
+		`output "subnet_cidrs" = ["10.0.0.0/24","10.0.1.0/24"], golden ["10.0.0.0/24"]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffGolden() = %v, want %v", got, want)
+	}
+}
+
+func TestAssertOutputsMatchGoldenUpdateThenCompare(t *testing.T) {
+	stubOutputs(t, map[string]string{"": sampleOutputsJSON})
+	goldenPath := filepath.Join(t.TempDir(), "testdata", "vpc.golden.json")
+
+	t.Setenv(updateGoldenEnv, "1")
+	AssertOutputsMatchGolden(t, &terraform.Options{}, goldenPath)
+
+	content, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "db_password": "<sensitive>",
+  "network_name": "test-vpc",
+  "subnet_cidrs": [
+    "10.0.0.0/24",
+    "10.0.1.0/24"
+  ]
+}
+`
+	if string(content) != want {
+		t.Errorf("golden file =\n%s\nwant\n%s", content, want)
+	}
+
+	t.Setenv(updateGoldenEnv, "")
+	AssertOutputsMatchGolden(t, &terraform.Options{}, goldenPath)
+}
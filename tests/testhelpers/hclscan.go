@@ -0,0 +1,164 @@
+package testhelpers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tfBlock is a top-level block in a .tf file, e.g. module "vpc" { ... },
+// with the attributes assigned directly inside it.
+//
+// This is a deliberately small line-based scanner for the static module
+// checks; it understands the formatting terraform fmt produces, not
+// arbitrary HCL.
+type tfBlock struct {
+	Type       string
+	Labels     []string
+	File       string
+	Line       int
+	Attributes map[string]string
+	// Nested holds the attributes of nested blocks keyed by block type,
+	// e.g. Nested["validation"] for a variable's validation block.
+	Nested map[string]map[string]string
+	// Body is the raw text between the block's braces.
+	Body string
+}
+
+var (
+	blockHeaderRe = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_-]*)((?:\s+"[^"]*")*)\s*\{\s*$`)
+	labelRe       = regexp.MustCompile(`"([^"]*)"`)
+	attributeRe   = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_-]*)\s*=\s*(.*)$`)
+)
+
+// braceDelta returns the change in brace depth on a line, ignoring braces in
+// string literals, ${...} interpolations and comments.
+func braceDelta(line string) int {
+	depth, inString := 0, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+			// Interpolation braces inside strings are balanced and ignored.
+		case c == '#' || (c == '/' && i+1 < len(line) && line[i+1] == '/'):
+			return depth
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// unquote strips surrounding quotes from a simple string attribute value.
+func unquote(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// parseTFFile returns the top-level blocks of a .tf file.
+func parseTFFile(path string) ([]tfBlock, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		blocks  []tfBlock
+		current *tfBlock
+		nested  string
+		depth   int
+		body    strings.Builder
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if current == nil {
+			if m := blockHeaderRe.FindStringSubmatch(trimmed); m != nil {
+				current = &tfBlock{Type: m[1], File: path, Line: lineNo,
+					Attributes: map[string]string{}, Nested: map[string]map[string]string{}}
+				for _, l := range labelRe.FindAllStringSubmatch(m[2], -1) {
+					current.Labels = append(current.Labels, l[1])
+				}
+				depth = 1
+				body.Reset()
+			}
+			continue
+		}
+
+		if depth == 1 {
+			if m := attributeRe.FindStringSubmatch(trimmed); m != nil {
+				current.Attributes[m[1]] = strings.TrimSpace(m[2])
+			} else if m := blockHeaderRe.FindStringSubmatch(trimmed); m != nil {
+				nested = m[1]
+				if current.Nested[nested] == nil {
+					current.Nested[nested] = map[string]string{}
+				}
+			}
+		} else if depth == 2 && nested != "" {
+			if m := attributeRe.FindStringSubmatch(trimmed); m != nil {
+				current.Nested[nested][m[1]] = strings.TrimSpace(m[2])
+			}
+		}
+
+		depth += braceDelta(line)
+		if depth <= 0 {
+			current.Body = body.String()
+			blocks = append(blocks, *current)
+			current, nested = nil, ""
+			continue
+		}
+		if depth == 1 {
+			nested = ""
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	return blocks, scanner.Err()
+}
+
+// parseModuleBlocks returns the top-level blocks of every .tf file directly
+// in modulePath, in file order.
+func parseModuleBlocks(modulePath string) ([]tfBlock, error) {
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var blocks []tfBlock
+	for _, file := range files {
+		fileBlocks, err := parseTFFile(file)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, fileBlocks...)
+	}
+	return blocks, nil
+}
+
+// blocksOfType filters blocks by type.
+func blocksOfType(blocks []tfBlock, blockType string) []tfBlock {
+	var out []tfBlock
+	for _, b := range blocks {
+		if b.Type == blockType {
+			out = append(out, b)
+		}
+	}
+	return out
+}
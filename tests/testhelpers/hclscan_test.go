@@ -0,0 +1,58 @@
+package testhelpers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTFFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "variables.tf")
+	writeFile(t, path, `# comment { not a block
+variable "prefix_length" {
+  description = "Prefix { length }"
+  type        = number
+  default     = 16
+  validation {
+    condition     = var.prefix_length >= 8
+    error_message = "Must be at least 8."
+  }
+}
+
+terraform {
+  required_version = ">= 1.5.0"
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+`)
+
+	blocks, err := parseTFFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("parsed %d blocks, want 2: %+v", len(blocks), blocks)
+	}
+
+	v := blocks[0]
+	if v.Type != "variable" || !reflect.DeepEqual(v.Labels, []string{"prefix_length"}) || v.Line != 2 {
+		t.Errorf("variable block = %s %v line %d", v.Type, v.Labels, v.Line)
+	}
+	if v.Attributes["type"] != "number" || unquote(v.Attributes["description"]) != "Prefix { length }" {
+		t.Errorf("variable attributes = %v", v.Attributes)
+	}
+	if v.Nested["validation"]["condition"] != "var.prefix_length >= 8" {
+		t.Errorf("validation attributes = %v", v.Nested["validation"])
+	}
+	if _, ok := v.Attributes["condition"]; ok {
+		t.Error("nested attribute leaked into the block's own attributes")
+	}
+
+	tf := blocks[1]
+	if tf.Type != "terraform" || unquote(tf.Attributes["required_version"]) != ">= 1.5.0" {
+		t.Errorf("terraform block = %+v", tf)
+	}
+}
@@ -0,0 +1,122 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// IAMPolicy is a resource IAM policy as returned by get-iam-policy.
+type IAMPolicy struct {
+	Bindings []IAMBinding `json:"bindings"`
+}
+
+// IAMBinding grants a role to a set of members.
+type IAMBinding struct {
+	Role    string   `json:"role"`
+	Members []string `json:"members"`
+}
+
+const tokenCreatorRole = "roles/iam.serviceAccountTokenCreator"
+
+// publicMembers are the IAM principals that grant access to anyone.
+var publicMembers = map[string]bool{"allUsers": true, "allAuthenticatedUsers": true}
+
+// PublicBuckets lists buckets that are intentionally public (e.g. static
+// website assets). AssertBucketNoPublicIAMMembers skips them.
+var PublicBuckets []string
+
+// serviceAccountMember returns the IAM member string for a service account
+// email, accepting an already-prefixed member.
+func serviceAccountMember(email string) string {
+	if strings.HasPrefix(email, "serviceAccount:") {
+		return email
+	}
+	return "serviceAccount:" + email
+}
+
+// hasBinding reports whether policy grants role to member.
+func hasBinding(policy IAMPolicy, role, member string) bool {
+	for _, b := range policy.Bindings {
+		if b.Role != role {
+			continue
+		}
+		for _, m := range b.Members {
+			if m == member {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AssertImpersonationAllowed fails the test unless sourceSA holds
+// roles/iam.serviceAccountTokenCreator on targetSA.
+func AssertImpersonationAllowed(t *testing.T, projectID, sourceSA, targetSA string) {
+	t.Helper()
+
+	var policy IAMPolicy
+	if err := gcloudJSON(&policy, "iam", "service-accounts", "get-iam-policy", targetSA, "--project", projectID); err != nil {
+		t.Fatalf("Failed to get IAM policy of service account %s: %v", targetSA, err)
+	}
+
+	member := serviceAccountMember(sourceSA)
+	allowed := hasBinding(policy, tokenCreatorRole, member)
+	t.Logf("Service account %s: %s has %s: %t", targetSA, member, tokenCreatorRole, allowed)
+	if !allowed {
+		t.Errorf("%s cannot impersonate %s: missing %s binding (bindings: %s)",
+			sourceSA, targetSA, tokenCreatorRole, describeBindings(policy))
+	}
+}
+
+// describeBindings renders the policy's bindings for failure messages.
+func describeBindings(policy IAMPolicy) string {
+	parts := make([]string, 0, len(policy.Bindings))
+	for _, b := range policy.Bindings {
+		parts = append(parts, fmt.Sprintf("%s=[%s]", b.Role, strings.Join(b.Members, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// publicGrants describes every binding in policy that grants a role to
+// allUsers or allAuthenticatedUsers.
+func publicGrants(policy IAMPolicy) []string {
+	var grants []string
+	for _, b := range policy.Bindings {
+		for _, m := range b.Members {
+			if publicMembers[m] {
+				grants = append(grants, fmt.Sprintf("%s granted to %s", b.Role, m))
+			}
+		}
+	}
+	return grants
+}
+
+// AssertNoPublicIAMMembers fails the test listing any binding in the policy
+// that grants a role to allUsers or allAuthenticatedUsers.
+func AssertNoPublicIAMMembers(t *testing.T, resourcePolicy IAMPolicy) {
+	t.Helper()
+
+	for _, g := range publicGrants(resourcePolicy) {
+		t.Errorf("Public IAM grant: %s", g)
+	}
+}
+
+// AssertBucketNoPublicIAMMembers applies AssertNoPublicIAMMembers to the
+// bucket's IAM policy unless the bucket is listed in PublicBuckets.
+func AssertBucketNoPublicIAMMembers(t *testing.T, projectID, bucket string) {
+	t.Helper()
+
+	for _, allowed := range PublicBuckets {
+		if allowed == bucket {
+			t.Logf("Bucket %s is allowlisted as public", bucket)
+			return
+		}
+	}
+
+	var policy IAMPolicy
+	if err := gcloudJSON(&policy, "storage", "buckets", "get-iam-policy", "gs://"+bucket, "--project", projectID); err != nil {
+		t.Fatalf("Failed to get IAM policy of bucket %s: %v", bucket, err)
+	}
+	AssertNoPublicIAMMembers(t, policy)
+}
@@ -0,0 +1,59 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestHasBindingTokenCreator(t *testing.T) {
+	var policy IAMPolicy
+	raw := `{"bindings":[
+		{"role":"roles/iam.serviceAccountUser","members":["serviceAccount:ci@p.iam.gserviceaccount.com"]},
+		{"role":"roles/iam.serviceAccountTokenCreator","members":["serviceAccount:deployer@p.iam.gserviceaccount.com","group:ops@example.com"]}]}`
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		source string
+		want   bool
+	}{
+		{"deployer@p.iam.gserviceaccount.com", true},
+		{"serviceAccount:deployer@p.iam.gserviceaccount.com", true},
+		{"ci@p.iam.gserviceaccount.com", false},
+	}
+	for _, tc := range cases {
+		if got := hasBinding(policy, tokenCreatorRole, serviceAccountMember(tc.source)); got != tc.want {
+			t.Errorf("hasBinding(%s) = %t, want %t", tc.source, got, tc.want)
+		}
+	}
+}
+
+func TestPublicGrants(t *testing.T) {
+	policy := IAMPolicy{Bindings: []IAMBinding{
+		{Role: "roles/storage.objectViewer", Members: []string{"allUsers", "group:web@example.com"}},
+		{Role: "roles/storage.legacyBucketReader", Members: []string{"allAuthenticatedUsers"}},
+		{Role: "roles/storage.admin", Members: []string{"user:ops@example.com"}},
+	}}
+	want := []string{
+		"roles/storage.objectViewer granted to allUsers",
+		"roles/storage.legacyBucketReader granted to allAuthenticatedUsers",
+	}
+	if got := publicGrants(policy); !reflect.DeepEqual(got, want) {
+		t.Errorf("publicGrants() = %v, want %v", got, want)
+	}
+
+	if got := publicGrants(IAMPolicy{Bindings: policy.Bindings[2:]}); len(got) != 0 {
+		t.Errorf("private policy: got %v", got)
+	}
+}
+
+func TestAssertBucketNoPublicIAMMembersAllowlist(t *testing.T) {
+	orig := PublicBuckets
+	PublicBuckets = []string{"www-assets"}
+	t.Cleanup(func() { PublicBuckets = orig })
+
+	stubGcloud(t, `{"bindings":[{"role":"roles/storage.objectViewer","members":["allUsers"]}]}`)
+	AssertBucketNoPublicIAMMembers(t, "p", "www-assets")
+}
@@ -0,0 +1,78 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// interconnectAttachment mirrors the fields of a Cloud Interconnect VLAN
+// attachment used by the assertions in this file.
+type interconnectAttachment struct {
+	Name                   string `json:"name"`
+	State                  string `json:"state"`
+	EdgeAvailabilityDomain string `json:"edgeAvailabilityDomain"`
+}
+
+// activeEdgeDomains returns the distinct edge availability domains of the
+// ACTIVE attachments. AVAILABILITY_DOMAIN_ANY names no particular domain
+// and is not counted.
+func activeEdgeDomains(attachments []interconnectAttachment) []string {
+	var domains []string
+	for _, a := range attachments {
+		if a.State == "ACTIVE" && a.EdgeAvailabilityDomain != "" && a.EdgeAvailabilityDomain != "AVAILABILITY_DOMAIN_ANY" {
+			domains = append(domains, a.EdgeAvailabilityDomain)
+		}
+	}
+	return distinct(domains)
+}
+
+// checkInterconnectRedundancy returns an error unless at least two
+// attachments are ACTIVE in different edge availability domains.
+func checkInterconnectRedundancy(attachments []interconnectAttachment) error {
+	active := 0
+	for _, a := range attachments {
+		if a.State == "ACTIVE" {
+			active++
+		}
+	}
+	if active < 2 {
+		return fmt.Errorf("%d active attachment(s), want at least 2", active)
+	}
+	if domains := activeEdgeDomains(attachments); len(domains) < 2 {
+		return fmt.Errorf("active attachments span %d edge availability domain(s) [%s], want at least 2",
+			len(domains), strings.Join(domains, ", "))
+	}
+	return nil
+}
+
+// AssertInterconnectRedundancy fails the test unless at least two of the
+// named VLAN attachments in region are ACTIVE in different edge
+// availability domains. Named attachments that don't exist are reported.
+func AssertInterconnectRedundancy(t *testing.T, projectID, region string, attachments []string) {
+	t.Helper()
+
+	var all []interconnectAttachment
+	if err := gcloudJSON(&all, "compute", "interconnects", "attachments", "list",
+		"--regions", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to list interconnect attachments in %s: %v", region, err)
+	}
+	byName := make(map[string]interconnectAttachment, len(all))
+	for _, a := range all {
+		byName[a.Name] = a
+	}
+
+	var selected []interconnectAttachment
+	for _, name := range attachments {
+		a, ok := byName[name]
+		if !ok {
+			t.Errorf("Interconnect attachment %s not found in %s", name, region)
+			continue
+		}
+		t.Logf("Interconnect attachment %s state=%s domain=%s", name, a.State, a.EdgeAvailabilityDomain)
+		selected = append(selected, a)
+	}
+	if err := checkInterconnectRedundancy(selected); err != nil {
+		t.Errorf("Interconnect attachments in %s: %v", region, err)
+	}
+}
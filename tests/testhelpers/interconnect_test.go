@@ -0,0 +1,48 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCheckInterconnectRedundancy(t *testing.T) {
+	cases := []struct {
+		name        string
+		raw         string
+		wantDomains []string
+		wantErr     bool
+	}{
+		{"redundant pair", `[
+			{"name":"a1","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_1"},
+			{"name":"a2","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_2"}]`,
+			[]string{"AVAILABILITY_DOMAIN_1", "AVAILABILITY_DOMAIN_2"}, false},
+		{"same domain", `[
+			{"name":"a1","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_1"},
+			{"name":"a2","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_1"}]`,
+			[]string{"AVAILABILITY_DOMAIN_1"}, true},
+		{"second not active", `[
+			{"name":"a1","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_1"},
+			{"name":"a2","state":"PENDING_PARTNER","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_2"}]`,
+			[]string{"AVAILABILITY_DOMAIN_1"}, true},
+		{"any domain", `[
+			{"name":"a1","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_ANY"},
+			{"name":"a2","state":"ACTIVE","edgeAvailabilityDomain":"AVAILABILITY_DOMAIN_2"}]`,
+			[]string{"AVAILABILITY_DOMAIN_2"}, true},
+		{"none", `[]`, nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attachments []interconnectAttachment
+			if err := json.Unmarshal([]byte(tc.raw), &attachments); err != nil {
+				t.Fatal(err)
+			}
+			if got := activeEdgeDomains(attachments); !reflect.DeepEqual(got, tc.wantDomains) {
+				t.Errorf("activeEdgeDomains() = %v, want %v", got, tc.wantDomains)
+			}
+			if err := checkInterconnectRedundancy(attachments); (err != nil) != tc.wantErr {
+				t.Errorf("checkInterconnectRedundancy() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
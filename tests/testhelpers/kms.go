@@ -0,0 +1,136 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// AllowedKMSKeyRings lists the key rings (projects/P/locations/L/keyRings/R)
+// that customer-managed encryption keys must come from. When empty, any key
+// ring is accepted.
+var AllowedKMSKeyRings []string
+
+// keyRingOf returns the key ring resource name of a crypto key or key
+// version, or "" if keyName is not a KMS key resource.
+func keyRingOf(keyName string) string {
+	i := strings.Index(keyName, "/cryptoKeys/")
+	if i < 0 {
+		return ""
+	}
+	return keyName[:i]
+}
+
+// keyRingAllowed reports whether keyName belongs to one of allowed, or
+// whether allowed is empty.
+func keyRingAllowed(keyName string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	ring := keyRingOf(keyName)
+	for _, a := range allowed {
+		if ring == a {
+			return true
+		}
+	}
+	return false
+}
+
+// kmsKey mirrors the fields of a Cloud KMS crypto key used by the assertions
+// in this file.
+type kmsKey struct {
+	Name            string `json:"name"`
+	Purpose         string `json:"purpose"`
+	VersionTemplate struct {
+		Algorithm       string `json:"algorithm"`
+		ProtectionLevel string `json:"protectionLevel"`
+	} `json:"versionTemplate"`
+	ImportOnly               bool   `json:"importOnly"`
+	DestroyScheduledDuration string `json:"destroyScheduledDuration"`
+}
+
+// defaultDestroyScheduledDuration applies to keys created without an
+// explicit destroy_scheduled_duration.
+const defaultDestroyScheduledDuration = 30 * 24 * time.Hour
+
+// destroyScheduledDuration returns how long the key's versions stay in
+// DESTROY_SCHEDULED before they are destroyed.
+func (k kmsKey) destroyScheduledDuration() (time.Duration, error) {
+	if k.DestroyScheduledDuration == "" {
+		return defaultDestroyScheduledDuration, nil
+	}
+	d, err := time.ParseDuration(k.DestroyScheduledDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid destroy scheduled duration %q", k.DestroyScheduledDuration)
+	}
+	return d, nil
+}
+
+// describeKMSKey describes a key given its full resource name
+// (projects/P/locations/L/keyRings/R/cryptoKeys/K).
+func describeKMSKey(t *testing.T, keyResource string) kmsKey {
+	t.Helper()
+
+	var key kmsKey
+	if err := gcloudJSON(&key, "kms", "keys", "describe", keyResource); err != nil {
+		t.Fatalf("Failed to describe KMS key %s: %v", keyResource, err)
+	}
+	return key
+}
+
+// checkKMSKeySpec returns the differences between the key's purpose and
+// algorithm and the expected ones.
+func checkKMSKeySpec(key kmsKey, expectedPurpose, expectedAlgorithm string) []string {
+	var problems []string
+	if key.Purpose != expectedPurpose {
+		problems = append(problems, fmt.Sprintf("purpose is %s, want %s", key.Purpose, expectedPurpose))
+	}
+	if key.VersionTemplate.Algorithm != expectedAlgorithm {
+		problems = append(problems, fmt.Sprintf("algorithm is %s, want %s", key.VersionTemplate.Algorithm, expectedAlgorithm))
+	}
+	return problems
+}
+
+// AssertKMSKeySpec fails the test if the key's purpose (e.g. ENCRYPT_DECRYPT,
+// ASYMMETRIC_SIGN) or version template algorithm differ from expected.
+func AssertKMSKeySpec(t *testing.T, keyResource, expectedPurpose, expectedAlgorithm string) {
+	t.Helper()
+
+	key := describeKMSKey(t, keyResource)
+	t.Logf("KMS key %s purpose=%s algorithm=%s", keyResource, key.Purpose, key.VersionTemplate.Algorithm)
+	for _, p := range checkKMSKeySpec(key, expectedPurpose, expectedAlgorithm) {
+		t.Errorf("KMS key %s: %s", keyResource, p)
+	}
+}
+
+// checkKMSKeyProtection returns the reasons the key's destroy protection
+// falls short: a destroy scheduled duration below minDestroyScheduled, or
+// import-only not set when requireImportOnly is.
+func checkKMSKeyProtection(key kmsKey, requireImportOnly bool, minDestroyScheduled time.Duration) []string {
+	var problems []string
+	if d, err := key.destroyScheduledDuration(); err != nil {
+		problems = append(problems, err.Error())
+	} else if d < minDestroyScheduled {
+		problems = append(problems, fmt.Sprintf("destroy scheduled duration is %s, want at least %s", d, minDestroyScheduled))
+	}
+	if requireImportOnly && !key.ImportOnly {
+		problems = append(problems, "key is not import-only")
+	}
+	return problems
+}
+
+// AssertKMSKeyProtection fails the test if the key's versions can be
+// destroyed sooner than minDestroyScheduledDuration after being scheduled
+// for destruction, or if requireImportOnly is set and the key accepts
+// versions generated by Cloud KMS.
+func AssertKMSKeyProtection(t *testing.T, keyResource string, requireImportOnly bool, minDestroyScheduledDuration time.Duration) {
+	t.Helper()
+
+	key := describeKMSKey(t, keyResource)
+	d, _ := key.destroyScheduledDuration()
+	t.Logf("KMS key %s destroyScheduledDuration=%s importOnly=%t", keyResource, d, key.ImportOnly)
+	for _, p := range checkKMSKeyProtection(key, requireImportOnly, minDestroyScheduledDuration) {
+		t.Errorf("KMS key %s: %s", keyResource, p)
+	}
+}
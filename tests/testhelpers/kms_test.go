@@ -0,0 +1,64 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestKeyRingOf(t *testing.T) {
+	cases := map[string]string{
+		"projects/p/locations/l/keyRings/r/cryptoKeys/k":                     "projects/p/locations/l/keyRings/r",
+		"projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1": "projects/p/locations/l/keyRings/r",
+		"not-a-key": "",
+	}
+	for in, want := range cases {
+		if got := keyRingOf(in); got != want {
+			t.Errorf("keyRingOf(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckKMSKeySpec(t *testing.T) {
+	var key kmsKey
+	raw := `{"purpose":"ASYMMETRIC_SIGN","versionTemplate":{"algorithm":"EC_SIGN_P256_SHA256","protectionLevel":"HSM"}}`
+	if err := json.Unmarshal([]byte(raw), &key); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := checkKMSKeySpec(key, "ASYMMETRIC_SIGN", "EC_SIGN_P256_SHA256"); len(got) != 0 {
+		t.Errorf("matching spec: got %v", got)
+	}
+	if got := checkKMSKeySpec(key, "ENCRYPT_DECRYPT", "GOOGLE_SYMMETRIC_ENCRYPTION"); len(got) != 2 {
+		t.Errorf("mismatched spec: got %v, want 2 problems", got)
+	}
+}
+
+func TestCheckKMSKeyProtection(t *testing.T) {
+	const day = 24 * time.Hour
+	cases := []struct {
+		name              string
+		raw               string
+		requireImportOnly bool
+		min               time.Duration
+		wantProblems      int
+	}{
+		{"long import-only", `{"importOnly":true,"destroyScheduledDuration":"7776000s"}`, true, 60 * day, 0},
+		{"default duration", `{}`, false, 30 * day, 0},
+		{"too short", `{"destroyScheduledDuration":"86400s"}`, false, 7 * day, 1},
+		{"not import-only", `{"destroyScheduledDuration":"2592000s"}`, true, 7 * day, 1},
+		{"neither", `{"destroyScheduledDuration":"86400s"}`, true, 30 * day, 2},
+		{"invalid duration", `{"destroyScheduledDuration":"a day"}`, false, day, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var key kmsKey
+			if err := json.Unmarshal([]byte(tc.raw), &key); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkKMSKeyProtection(key, tc.requireImportOnly, tc.min); len(got) != tc.wantProblems {
+				t.Errorf("checkKMSKeyProtection() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
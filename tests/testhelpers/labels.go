@@ -0,0 +1,153 @@
+package testhelpers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+// environmentLabel is expected to differ between environments.
+const environmentLabel = "environment"
+
+// normalizeLabelValue replaces the environment name in a label value with a
+// placeholder, so "app-dev" and "app-prod" compare equal. Only whole
+// segments delimited by "-" or "_" are replaced, so "devops" and "product"
+// are left alone.
+func normalizeLabelValue(value, env string) string {
+	if env == "" {
+		return value
+	}
+	var b strings.Builder
+	start := 0
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) && value[i] != '-' && value[i] != '_' {
+			continue
+		}
+		if segment := value[start:i]; segment == env {
+			b.WriteString("{env}")
+		} else {
+			b.WriteString(segment)
+		}
+		if i < len(value) {
+			b.WriteByte(value[i])
+		}
+		start = i + 1
+	}
+	return b.String()
+}
+
+// labelDivergences returns, for each key, a description of environments
+// where the label is missing or its env-normalized value differs from the
+// others.
+func labelDivergences(perEnv map[string]map[string]string, keys []string) []string {
+	envs := make([]string, 0, len(perEnv))
+	for env := range perEnv {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	var divergences []string
+	for _, key := range keys {
+		if key == environmentLabel {
+			continue
+		}
+
+		var missing []string
+		byValue := make(map[string][]string)
+		for _, env := range envs {
+			value, ok := perEnv[env][key]
+			if !ok {
+				missing = append(missing, env)
+				continue
+			}
+			norm := normalizeLabelValue(value, env)
+			byValue[norm] = append(byValue[norm], fmt.Sprintf("%s=%q", env, value))
+		}
+
+		if len(missing) > 0 {
+			divergences = append(divergences, fmt.Sprintf("label %q missing in %s", key, strings.Join(missing, ", ")))
+		}
+		if len(byValue) > 1 {
+			var variants []string
+			for _, envValues := range byValue {
+				variants = append(variants, strings.Join(envValues, ", "))
+			}
+			sort.Strings(variants)
+			divergences = append(divergences, fmt.Sprintf("label %q diverges: %s", key, strings.Join(variants, "; ")))
+		}
+	}
+	return divergences
+}
+
+// AssertLabelConsistency fails the test if any of keys is missing from an
+// environment or carries a differently-shaped value across environments.
+// perEnv maps environment name (dev, staging, prod) to the resource's
+// labels. Values may embed the environment name, and the environment label
+// itself is allowed to differ.
+func AssertLabelConsistency(t *testing.T, perEnv map[string]map[string]string, keys []string) {
+	t.Helper()
+
+	for _, d := range labelDivergences(perEnv, keys) {
+		t.Error(d)
+	}
+}
+
+// Limits GCP enforces on resource labels.
+const (
+	maxLabels           = 64
+	maxLabelLength      = 63
+	labelCharsetPattern = `^[\p{Ll}\p{Lo}0-9_-]*$`
+)
+
+var labelCharsetRe = regexp.MustCompile(labelCharsetPattern)
+
+// labelViolations returns, sorted by key, a description of every label key
+// or value GCP would reject: keys must be 1-63 characters starting with a
+// lowercase letter, values at most 63 characters, and both may only use
+// lowercase letters, digits, underscores and dashes.
+func labelViolations(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var violations []string
+	if len(labels) > maxLabels {
+		violations = append(violations, fmt.Sprintf("%d labels, at most %d allowed", len(labels), maxLabels))
+	}
+	for _, key := range keys {
+		value := labels[key]
+		switch first, _ := utf8.DecodeRuneInString(key); {
+		case key == "":
+			violations = append(violations, "empty label key")
+		case utf8.RuneCountInString(key) > maxLabelLength:
+			violations = append(violations, fmt.Sprintf("key %q is %d characters, at most %d allowed", key, utf8.RuneCountInString(key), maxLabelLength))
+		case !labelCharsetRe.MatchString(key):
+			violations = append(violations, fmt.Sprintf("key %q may only contain lowercase letters, digits, _ and -", key))
+		case !unicode.IsLetter(first):
+			violations = append(violations, fmt.Sprintf("key %q must start with a lowercase letter", key))
+		}
+		switch {
+		case utf8.RuneCountInString(value) > maxLabelLength:
+			violations = append(violations, fmt.Sprintf("value of %q is %d characters, at most %d allowed", key, utf8.RuneCountInString(value), maxLabelLength))
+		case !labelCharsetRe.MatchString(value):
+			violations = append(violations, fmt.Sprintf("value %q of %q may only contain lowercase letters, digits, _ and -", value, key))
+		}
+	}
+	return violations
+}
+
+// AssertLabelsValid fails the test listing every label key or value that
+// GCP would reject for its length or characters.
+func AssertLabelsValid(t *testing.T, labels map[string]string) {
+	t.Helper()
+
+	for _, v := range labelViolations(labels) {
+		t.Errorf("Invalid label: %s", v)
+	}
+}
@@ -0,0 +1,93 @@
+package testhelpers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLabelValue(t *testing.T) {
+	cases := []struct{ value, env, want string }{
+		{"app-dev", "dev", "app-{env}"},
+		{"dev_app", "dev", "{env}_app"},
+		{"prod", "prod", "{env}"},
+		{"devops", "dev", "devops"},
+		{"product-prod", "prod", "product-{env}"},
+		{"app--dev", "dev", "app--{env}"},
+		{"app", "", "app"},
+	}
+	for _, tc := range cases {
+		if got := normalizeLabelValue(tc.value, tc.env); got != tc.want {
+			t.Errorf("normalizeLabelValue(%q, %q) = %q, want %q", tc.value, tc.env, got, tc.want)
+		}
+	}
+}
+
+func TestLabelDivergences(t *testing.T) {
+	consistent := map[string]map[string]string{
+		"dev":     {"environment": "dev", "team": "platform", "app": "cataziza-platform-dev"},
+		"staging": {"environment": "staging", "team": "platform", "app": "cataziza-platform-staging"},
+		"prod":    {"environment": "prod", "team": "platform", "app": "cataziza-platform-prod"},
+	}
+	if got := labelDivergences(consistent, []string{"environment", "team", "app"}); len(got) != 0 {
+		t.Errorf("consistent labels: got %v", got)
+	}
+
+	// Values containing an environment name as a substring are identical
+	// everywhere and must not be normalized in just one environment.
+	substrings := map[string]map[string]string{
+		"dev":     {"team": "devops", "line": "product"},
+		"staging": {"team": "devops", "line": "product"},
+		"prod":    {"team": "devops", "line": "product"},
+	}
+	if got := labelDivergences(substrings, []string{"team", "line"}); len(got) != 0 {
+		t.Errorf("substring labels: got %v", got)
+	}
+
+	divergent := map[string]map[string]string{
+		"dev":  {"environment": "dev", "team": "platform", "cost-center": "cc-100"},
+		"prod": {"environment": "prod", "team": "Platform"},
+	}
+	got := labelDivergences(divergent, []string{"team", "cost-center"})
+	want := []string{
+		`label "team" diverges: dev="platform"; prod="Platform"`,
+		`label "cost-center" missing in prod`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("labelDivergences() = %v, want %v", got, want)
+	}
+}
+
+func TestLabelViolations(t *testing.T) {
+	cases := []struct {
+		name   string
+		labels map[string]string
+		want   int
+	}{
+		{"valid", map[string]string{"environment": "prod", "cost_center": "cc-1234", "team": ""}, 0},
+		{"international lowercase", map[string]string{"équipe": "données"}, 0},
+		{"63 characters", map[string]string{strings.Repeat("k", 63): strings.Repeat("v", 63)}, 0},
+		{"64 characters", map[string]string{strings.Repeat("k", 64): strings.Repeat("v", 64)}, 2},
+		{"uppercase", map[string]string{"Environment": "Prod"}, 2},
+		{"disallowed characters", map[string]string{"app.name": "web app"}, 2},
+		{"leading digit", map[string]string{"1st": "a"}, 1},
+		{"leading dash", map[string]string{"-tier": "a"}, 1},
+		{"empty key", map[string]string{"": "a"}, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := labelViolations(tc.labels); len(got) != tc.want {
+				t.Errorf("labelViolations() = %v, want %d violation(s)", got, tc.want)
+			}
+		})
+	}
+
+	tooMany := make(map[string]string, 65)
+	for i := 0; i < 65; i++ {
+		tooMany[fmt.Sprintf("label%d", i)] = "x"
+	}
+	if got := labelViolations(tooMany); len(got) != 1 {
+		t.Errorf("labelViolations(65 labels) = %v, want 1 violation", got)
+	}
+}
@@ -0,0 +1,270 @@
+package testhelpers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lbHTTPClient returns a client that sends every request to ip regardless of
+// the URL host, so the load balancer can be tested before DNS points at it.
+// Redirects are returned rather than followed. Unit tests replace it.
+var lbHTTPClient = func(ip string) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				_, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				return dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkHTTPSRedirect validates a response to a plain HTTP request and returns
+// the redirect target. The response must be a permanent redirect (301/308)
+// to the https scheme of the same host.
+func checkHTTPSRedirect(status int, location, host string) (*url.URL, error) {
+	if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+		return nil, fmt.Errorf("expected 301 or 308 redirect, got %d", status)
+	}
+	if location == "" {
+		return nil, fmt.Errorf("redirect response has no Location header")
+	}
+	target, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Location header %q: %w", location, err)
+	}
+	if target.Scheme != "https" {
+		return nil, fmt.Errorf("redirect target %q does not use https", location)
+	}
+	if !strings.EqualFold(target.Hostname(), host) {
+		return nil, fmt.Errorf("redirect target host %q does not match %q", target.Hostname(), host)
+	}
+	return target, nil
+}
+
+// AssertHTTPSRedirect issues a plain HTTP request for host to the load
+// balancer at ip and asserts it redirects to https on the same host. The
+// redirect is followed once and the target must answer without redirecting
+// again.
+func AssertHTTPSRedirect(t *testing.T, ip, host string) {
+	t.Helper()
+
+	client := lbHTTPClient(ip)
+	resp, err := client.Get("http://" + host + "/")
+	if err != nil {
+		t.Fatalf("HTTP request to %s (%s) failed: %v", host, ip, err)
+	}
+	resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	t.Logf("HTTP request to %s returned %d, Location %q", host, resp.StatusCode, location)
+	target, err := checkHTTPSRedirect(resp.StatusCode, location, host)
+	if err != nil {
+		t.Errorf("Load balancer %s: %v", ip, err)
+		return
+	}
+
+	resp, err = client.Get(target.String())
+	if err != nil {
+		t.Errorf("Following redirect to %s failed: %v", target, err)
+		return
+	}
+	resp.Body.Close()
+
+	t.Logf("Redirect target %s returned %d", target, resp.StatusCode)
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		t.Errorf("Redirect target %s redirected again (%d)", target, resp.StatusCode)
+	}
+}
+
+// backendService mirrors the fields of a backend service used by the
+// assertions in this file.
+type backendService struct {
+	Name     string      `json:"name"`
+	Backends []lbBackend `json:"backends"`
+}
+
+// lbBackend is one backend of a backend service. Capacity fields are
+// pointers because the API omits the ones not used by the balancing mode.
+type lbBackend struct {
+	Group                     string   `json:"group"`
+	BalancingMode             string   `json:"balancingMode"`
+	MaxUtilization            *float64 `json:"maxUtilization"`
+	MaxRate                   *int     `json:"maxRate"`
+	MaxRatePerInstance        *float64 `json:"maxRatePerInstance"`
+	MaxRatePerEndpoint        *float64 `json:"maxRatePerEndpoint"`
+	MaxConnections            *int     `json:"maxConnections"`
+	MaxConnectionsPerInstance *int     `json:"maxConnectionsPerInstance"`
+	MaxConnectionsPerEndpoint *int     `json:"maxConnectionsPerEndpoint"`
+	CapacityScaler            *float64 `json:"capacityScaler"`
+}
+
+func (b lbBackend) hasRateCapacity() bool {
+	return b.MaxRate != nil || b.MaxRatePerInstance != nil || b.MaxRatePerEndpoint != nil
+}
+
+func (b lbBackend) hasConnectionCapacity() bool {
+	return b.MaxConnections != nil || b.MaxConnectionsPerInstance != nil || b.MaxConnectionsPerEndpoint != nil
+}
+
+// networkEndpointGroup mirrors the fields of a NEG used by the assertions in
+// this file. Size is absent for serverless NEGs.
+type networkEndpointGroup struct {
+	Name                string `json:"name"`
+	NetworkEndpointType string `json:"networkEndpointType"`
+	Size                *int   `json:"size"`
+}
+
+func describeBackendService(t *testing.T, projectID, name string) backendService {
+	t.Helper()
+
+	var svc backendService
+	if err := gcloudJSON(&svc, "compute", "backend-services", "describe", name, "--global", "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe backend service %s: %v", name, err)
+	}
+	return svc
+}
+
+// backendNEGs returns the URLs of the service's backends that are network
+// endpoint groups.
+func backendNEGs(svc backendService) []string {
+	var negs []string
+	for _, b := range svc.Backends {
+		if strings.Contains(b.Group, "/networkEndpointGroups/") {
+			negs = append(negs, b.Group)
+		}
+	}
+	return negs
+}
+
+// compareNEGBackends returns the differences between the NEGs referenced by
+// a backend service and the expected NEG names, plus any referenced NEG
+// reported as having no endpoints.
+func compareNEGBackends(negURLs []string, expectNEGs []string, negs map[string]networkEndpointGroup) []string {
+	actual := make([]string, 0, len(negURLs))
+	for _, u := range negURLs {
+		actual = append(actual, lastSegment(u))
+	}
+
+	var problems []string
+	for _, name := range missingFrom(expectNEGs, actual) {
+		problems = append(problems, fmt.Sprintf("expected NEG %s is not a backend", name))
+	}
+	for _, name := range missingFrom(actual, expectNEGs) {
+		problems = append(problems, fmt.Sprintf("unexpected NEG backend %s", name))
+	}
+
+	var empty []string
+	for _, name := range actual {
+		if neg, ok := negs[name]; ok && neg.Size != nil && *neg.Size == 0 {
+			empty = append(empty, name)
+		}
+	}
+	sort.Strings(empty)
+	for _, name := range empty {
+		problems = append(problems, fmt.Sprintf("NEG %s has no endpoints", name))
+	}
+	return problems
+}
+
+// AssertNEGBackend fails the test unless the global backend service is
+// backed by exactly the expected network endpoint groups and each of them
+// has at least one endpoint.
+func AssertNEGBackend(t *testing.T, projectID, backendService string, expectNEGs []string) {
+	t.Helper()
+
+	negURLs := backendNEGs(describeBackendService(t, projectID, backendService))
+	negs := make(map[string]networkEndpointGroup, len(negURLs))
+	for _, u := range negURLs {
+		var neg networkEndpointGroup
+		if err := gcloudJSON(&neg, "compute", "network-endpoint-groups", "describe", u); err != nil {
+			t.Fatalf("Failed to describe network endpoint group %s: %v", u, err)
+		}
+		negs[lastSegment(u)] = neg
+		if neg.Size != nil {
+			t.Logf("Backend service %s: NEG %s (%s) has %d endpoint(s)", backendService, neg.Name, neg.NetworkEndpointType, *neg.Size)
+		} else {
+			t.Logf("Backend service %s: NEG %s (%s)", backendService, neg.Name, neg.NetworkEndpointType)
+		}
+	}
+
+	for _, p := range compareNEGBackends(negURLs, expectNEGs, negs) {
+		t.Errorf("Backend service %s: %s", backendService, p)
+	}
+}
+
+// checkBackendCapacity returns, per backend, the differences from the
+// expected balancing mode and the capacity settings that mode needs. For
+// UTILIZATION the target maxUtilization must equal maxUtilization.
+func checkBackendCapacity(svc backendService, expectMode string, maxUtilization float64) []string {
+	if len(svc.Backends) == 0 {
+		return []string{"backend service has no backends"}
+	}
+
+	var problems []string
+	for _, b := range svc.Backends {
+		name := lastSegment(b.Group)
+		if b.BalancingMode != expectMode {
+			problems = append(problems, fmt.Sprintf("%s: balancing mode is %s, want %s", name, b.BalancingMode, expectMode))
+			continue
+		}
+		switch expectMode {
+		case "UTILIZATION":
+			if b.MaxUtilization == nil || math.Abs(*b.MaxUtilization-maxUtilization) > 1e-6 {
+				actual := "unset"
+				if b.MaxUtilization != nil {
+					actual = fmt.Sprint(*b.MaxUtilization)
+				}
+				problems = append(problems, fmt.Sprintf("%s: max utilization is %s, want %v", name, actual, maxUtilization))
+			}
+		case "RATE":
+			if !b.hasRateCapacity() {
+				problems = append(problems, fmt.Sprintf("%s: RATE mode without a max rate", name))
+			}
+		case "CONNECTION":
+			if !b.hasConnectionCapacity() {
+				problems = append(problems, fmt.Sprintf("%s: CONNECTION mode without max connections", name))
+			}
+		}
+		if b.CapacityScaler != nil && *b.CapacityScaler == 0 {
+			problems = append(problems, fmt.Sprintf("%s: capacity scaler is 0 (backend drained)", name))
+		}
+	}
+	return problems
+}
+
+// AssertBackendCapacity fails the test unless every backend of the global
+// backend service uses expectMode (UTILIZATION, RATE or CONNECTION) with the
+// capacity settings it needs; UTILIZATION backends must target
+// maxUtilization.
+func AssertBackendCapacity(t *testing.T, projectID, backendService string, expectMode string, maxUtilization float64) {
+	t.Helper()
+
+	svc := describeBackendService(t, projectID, backendService)
+	for _, b := range svc.Backends {
+		util := "unset"
+		if b.MaxUtilization != nil {
+			util = fmt.Sprint(*b.MaxUtilization)
+		}
+		t.Logf("Backend service %s: %s mode=%s maxUtilization=%s", backendService, lastSegment(b.Group), b.BalancingMode, util)
+	}
+	for _, p := range checkBackendCapacity(svc, expectMode, maxUtilization) {
+		t.Errorf("Backend service %s: %s", backendService, p)
+	}
+}
@@ -0,0 +1,123 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCheckHTTPSRedirect(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		location string
+		wantErr  bool
+	}{
+		{"301 to https", 301, "https://app.example.com/", false},
+		{"308 to https", 308, "https://APP.example.com/path", false},
+		{"302 is not permanent", 302, "https://app.example.com/", true},
+		{"no redirect", 200, "", true},
+		{"stays on http", 301, "http://app.example.com/", true},
+		{"different host", 301, "https://other.example.com/", true},
+		{"missing location", 301, "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := checkHTTPSRedirect(tc.status, tc.location, "app.example.com"); (err != nil) != tc.wantErr {
+				t.Errorf("checkHTTPSRedirect() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssertHTTPSRedirectFollowsOnce(t *testing.T) {
+	var requested []string
+	orig := lbHTTPClient
+	lbHTTPClient = func(string) *http.Client {
+		return &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.String())
+				resp := &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+				if req.URL.Scheme == "http" {
+					resp.StatusCode = 301
+					resp.Header.Set("Location", "https://app.example.com/")
+				}
+				return resp, nil
+			}),
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	t.Cleanup(func() { lbHTTPClient = orig })
+
+	AssertHTTPSRedirect(t, "203.0.113.10", "app.example.com")
+
+	if len(requested) != 2 || requested[1] != "https://app.example.com/" {
+		t.Errorf("requests = %v, want the http request then the https target", requested)
+	}
+}
+
+func TestCompareNEGBackends(t *testing.T) {
+	var svc backendService
+	raw := `{"name":"api","backends":[
+		{"group":"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-b/networkEndpointGroups/api-neg-b"},
+		{"group":"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-c/networkEndpointGroups/api-neg-c"},
+		{"group":"https://www.googleapis.com/compute/v1/projects/p/regions/europe-west1/instanceGroups/legacy-ig"}]}`
+	if err := json.Unmarshal([]byte(raw), &svc); err != nil {
+		t.Fatal(err)
+	}
+	negURLs := backendNEGs(svc)
+	if len(negURLs) != 2 {
+		t.Fatalf("backendNEGs() = %v, want the two NEG backends", negURLs)
+	}
+
+	zero, three := 0, 3
+	negs := map[string]networkEndpointGroup{
+		"api-neg-b": {Name: "api-neg-b", Size: &three},
+		"api-neg-c": {Name: "api-neg-c", Size: &zero},
+	}
+
+	if got := compareNEGBackends(negURLs, []string{"api-neg-b"}, map[string]networkEndpointGroup{"api-neg-b": negs["api-neg-b"]}); !reflect.DeepEqual(got, []string{"unexpected NEG backend api-neg-c"}) {
+		t.Errorf("extra NEG: got %v", got)
+	}
+
+	got := compareNEGBackends(negURLs, []string{"api-neg-b", "api-neg-c", "api-neg-d"}, negs)
+	want := []string{"expected NEG api-neg-d is not a backend", "NEG api-neg-c has no endpoints"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compareNEGBackends() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckBackendCapacity(t *testing.T) {
+	cases := []struct {
+		name         string
+		backends     string
+		mode         string
+		wantProblems int
+	}{
+		{"utilization matches", `[{"group":"ig-a","balancingMode":"UTILIZATION","maxUtilization":0.8,"capacityScaler":1}]`, "UTILIZATION", 0},
+		{"utilization differs", `[{"group":"ig-a","balancingMode":"UTILIZATION","maxUtilization":0.6}]`, "UTILIZATION", 1},
+		{"wrong mode", `[{"group":"ig-a","balancingMode":"RATE","maxRatePerInstance":100}]`, "UTILIZATION", 1},
+		{"rate with capacity", `[{"group":"neg-a","balancingMode":"RATE","maxRatePerEndpoint":50}]`, "RATE", 0},
+		{"rate without capacity", `[{"group":"neg-a","balancingMode":"RATE"}]`, "RATE", 1},
+		{"connection drained", `[{"group":"ig-a","balancingMode":"CONNECTION","maxConnections":1000,"capacityScaler":0}]`, "CONNECTION", 1},
+		{"no backends", `[]`, "UTILIZATION", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var svc backendService
+			if err := json.Unmarshal([]byte(`{"backends":`+tc.backends+`}`), &svc); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkBackendCapacity(svc, tc.mode, 0.8); len(got) != tc.wantProblems {
+				t.Errorf("checkBackendCapacity() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// LocatedResourceRef identifies a resource and the location GCP reports for
+// it. Location may be a region, a zone, a multi-region or a self link to
+// either.
+type LocatedResourceRef struct {
+	Type     string
+	Name     string
+	Location string
+}
+
+func (r LocatedResourceRef) String() string {
+	if r.Type == "" {
+		return r.Name
+	}
+	return r.Type + "/" + r.Name
+}
+
+// locationMatches reports whether actual lies in expected. Locations compare
+// case-insensitively (Cloud Storage reports upper case) and a zone matches
+// its parent region.
+func locationMatches(actual, expected string) bool {
+	actual = strings.ToLower(lastSegment(actual))
+	expected = strings.ToLower(lastSegment(expected))
+	if actual == expected {
+		return true
+	}
+	// Zones are "<region>-<letter>", e.g. europe-west1-b.
+	region, zone, ok := cutLast(actual, "-")
+	return ok && len(zone) == 1 && region == expected
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// locationMismatches describes every ref outside expectedLocation.
+func locationMismatches(refs []LocatedResourceRef, expectedLocation string) []string {
+	var mismatches []string
+	for _, r := range refs {
+		if !locationMatches(r.Location, expectedLocation) {
+			mismatches = append(mismatches, fmt.Sprintf("%s is in %q", r, r.Location))
+		}
+	}
+	return mismatches
+}
+
+// AssertResourceLocation fails the test listing every resource whose
+// location is not expectedLocation. Zonal resources match their region.
+func AssertResourceLocation(t *testing.T, refs []LocatedResourceRef, expectedLocation string) {
+	t.Helper()
+
+	for _, m := range locationMismatches(refs, expectedLocation) {
+		t.Errorf("Expected location %s: %s", expectedLocation, m)
+	}
+}
+
+// ScopedResourceRef identifies a resource by its self link or relative
+// name, from which its scope is derived.
+type ScopedResourceRef struct {
+	Type     string
+	Name     string
+	SelfLink string
+}
+
+func (r ScopedResourceRef) String() string {
+	return LocatedResourceRef{Type: r.Type, Name: r.Name}.String()
+}
+
+// resourceScope classifies a Compute self link or relative name as
+// "global", "regional" or "zonal", or "" if it names none of them.
+func resourceScope(selfLink string) string {
+	segments := strings.Split(selfLink, "/")
+	for i, s := range segments {
+		switch {
+		case s == "global":
+			return "global"
+		case s == "regions" && i+1 < len(segments):
+			return "regional"
+		case s == "zones" && i+1 < len(segments):
+			return "zonal"
+		}
+	}
+	return ""
+}
+
+// scopeMismatches describes every ref whose scope is not expectedScope.
+func scopeMismatches(refs []ScopedResourceRef, expectedScope string) []string {
+	var mismatches []string
+	for _, r := range refs {
+		scope := resourceScope(r.SelfLink)
+		if scope == "" {
+			mismatches = append(mismatches, fmt.Sprintf("%s has no scope in %q", r, r.SelfLink))
+		} else if !strings.EqualFold(scope, expectedScope) {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %s", r, scope))
+		}
+	}
+	return mismatches
+}
+
+// AssertResourceScope fails the test listing every resource that is not
+// expectedScope ("global", "regional" or "zonal"), as derived from its
+// self link.
+func AssertResourceScope(t *testing.T, refs []ScopedResourceRef, expectedScope string) {
+	t.Helper()
+
+	for _, m := range scopeMismatches(refs, expectedScope) {
+		t.Errorf("Expected %s scope: %s", expectedScope, m)
+	}
+}
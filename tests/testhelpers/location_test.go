@@ -0,0 +1,67 @@
+package testhelpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocationMatches(t *testing.T) {
+	cases := []struct {
+		actual, expected string
+		want             bool
+	}{
+		{"europe-west1", "europe-west1", true},
+		{"EUROPE-WEST1", "europe-west1", true},
+		{"europe-west1-b", "europe-west1", true},
+		{"https://www.googleapis.com/compute/v1/projects/p/zones/europe-west1-c", "europe-west1", true},
+		{"europe-west3-a", "europe-west1", false},
+		{"europe-west1-b", "europe-west1-c", false},
+		{"EU", "europe-west1", false},
+	}
+	for _, tc := range cases {
+		if got := locationMatches(tc.actual, tc.expected); got != tc.want {
+			t.Errorf("locationMatches(%q, %q) = %t, want %t", tc.actual, tc.expected, got, tc.want)
+		}
+	}
+}
+
+func TestLocationMismatches(t *testing.T) {
+	refs := []LocatedResourceRef{
+		{Type: "instance", Name: "web-1", Location: "europe-west1-b"},
+		{Type: "bucket", Name: "assets", Location: "US"},
+		{Name: "subnet-a", Location: "europe-west3"},
+	}
+	got := locationMismatches(refs, "europe-west1")
+	want := []string{`bucket/assets is in "US"`, `subnet-a is in "europe-west3"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("locationMismatches() = %v, want %v", got, want)
+	}
+}
+
+func TestResourceScope(t *testing.T) {
+	cases := map[string]string{
+		"https://www.googleapis.com/compute/v1/projects/p/global/backendServices/web":         "global",
+		"projects/p/global/networks/vpc":                                                      "global",
+		"https://www.googleapis.com/compute/v1/projects/p/regions/europe-west1/subnetworks/a": "regional",
+		"projects/p/zones/europe-west1-b/instances/web-1":                                     "zonal",
+		"web-1": "",
+	}
+	for in, want := range cases {
+		if got := resourceScope(in); got != want {
+			t.Errorf("resourceScope(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestScopeMismatches(t *testing.T) {
+	refs := []ScopedResourceRef{
+		{Type: "backendService", Name: "web", SelfLink: "projects/p/global/backendServices/web"},
+		{Type: "backendService", Name: "internal", SelfLink: "projects/p/regions/europe-west1/backendServices/internal"},
+		{Name: "ip", SelfLink: "ip"},
+	}
+	got := scopeMismatches(refs, "global")
+	want := []string{"backendService/internal is regional", `ip has no scope in "ip"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scopeMismatches() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,306 @@
+package testhelpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// hasTerraformFiles reports whether dir directly contains a .tf file.
+func hasTerraformFiles(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	return len(files) > 0
+}
+
+// discoverExamples returns the directories under modulePath/examples that
+// contain Terraform configuration, sorted. A module without an examples
+// directory has none.
+func discoverExamples(modulePath string) ([]string, error) {
+	root := filepath.Join(modulePath, "examples")
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var examples []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".terraform" {
+			return filepath.SkipDir
+		}
+		if hasTerraformFiles(path) {
+			examples = append(examples, path)
+		}
+		return nil
+	})
+	sort.Strings(examples)
+	return examples, err
+}
+
+// validateExample runs terraform init without a backend and terraform
+// validate in dir.
+func validateExample(t *testing.T, dir string) error {
+	opts := &terraform.Options{TerraformDir: dir, NoColor: true}
+	if _, err := terraformRunner(t, opts, "init", "-backend=false", "-input=false"); err != nil {
+		return err
+	}
+	_, err := terraformRunner(t, opts, "validate")
+	return err
+}
+
+// ValidateModuleExamples runs terraform validate in every example under
+// modulePath/examples and fails listing the examples that don't validate.
+func ValidateModuleExamples(t *testing.T, modulePath string) {
+	t.Helper()
+
+	examples, err := discoverExamples(modulePath)
+	if err != nil {
+		t.Fatalf("Failed to discover examples in %s: %v", modulePath, err)
+	}
+	if len(examples) == 0 {
+		t.Logf("Module %s has no examples to validate", modulePath)
+		return
+	}
+
+	var broken []string
+	for _, dir := range examples {
+		if err := validateExample(t, dir); err != nil {
+			t.Logf("Example %s failed validation: %v", dir, err)
+			broken = append(broken, dir)
+		}
+	}
+	if len(broken) > 0 {
+		t.Errorf("Module %s has %d example(s) that don't validate: %s",
+			modulePath, len(broken), strings.Join(broken, ", "))
+	}
+}
+
+// registrySourceRe matches registry module sources: NAMESPACE/NAME/PROVIDER,
+// optionally prefixed with a registry hostname and suffixed with a
+// //SUBDIR submodule path.
+var registrySourceRe = regexp.MustCompile(`^([a-z0-9.-]+\.[a-z]+/)?[A-Za-z0-9_-]+/[A-Za-z0-9_-]+/[A-Za-z0-9_-]+(//[^?]+)?$`)
+
+// isGitSource reports whether a module source is fetched from git.
+func isGitSource(source string) bool {
+	for _, prefix := range []string{"git::", "git@", "github.com/", "bitbucket.org/"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleSourcePinProblem returns why a module block's source is unpinned, or
+// "" if it is pinned or local. Git sources need a ?ref=, registry sources a
+// version argument. checked is false for other sources (archives, buckets,
+// Mercurial), whose pins can't be checked.
+func moduleSourcePinProblem(b tfBlock) (problem string, checked bool) {
+	source := unquote(b.Attributes["source"])
+	switch {
+	case strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../"):
+		return "", true
+	case isGitSource(source):
+		if !strings.Contains(source, "?ref=") && !strings.Contains(source, "&ref=") {
+			return fmt.Sprintf("git source %q has no ?ref= pin", source), true
+		}
+	case registrySourceRe.MatchString(source):
+		if unquote(b.Attributes["version"]) == "" {
+			return fmt.Sprintf("registry source %q has no version", source), true
+		}
+	default:
+		return "", false
+	}
+	return "", true
+}
+
+// unpinnedModules describes every module block whose source is not pinned,
+// and separately every block whose source can't be checked.
+func unpinnedModules(blocks []tfBlock) (unpinned, unchecked []string) {
+	for _, b := range blocksOfType(blocks, "module") {
+		where := fmt.Sprintf("%s:%d module %q", filepath.Base(b.File), b.Line, strings.Join(b.Labels, "."))
+		problem, checked := moduleSourcePinProblem(b)
+		switch {
+		case !checked:
+			unchecked = append(unchecked, fmt.Sprintf("%s: source %q is not a local, git or registry source", where, unquote(b.Attributes["source"])))
+		case problem != "":
+			unpinned = append(unpinned, fmt.Sprintf("%s: %s", where, problem))
+		}
+	}
+	return unpinned, unchecked
+}
+
+// AssertModuleSourcesPinned fails the test listing every module block in
+// modulePath whose git source lacks a ?ref= or whose registry source lacks
+// a version. Local sources are always accepted; other sources are logged
+// since their pins can't be checked.
+func AssertModuleSourcesPinned(t *testing.T, modulePath string) {
+	t.Helper()
+
+	blocks, err := parseModuleBlocks(modulePath)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", modulePath, err)
+	}
+	unpinned, unchecked := unpinnedModules(blocks)
+	for _, u := range unchecked {
+		t.Logf("Can't check module source pin in %s: %s", modulePath, u)
+	}
+	for _, u := range unpinned {
+		t.Errorf("Unpinned module source in %s: %s", modulePath, u)
+	}
+}
+
+const lockFileName = ".terraform.lock.hcl"
+
+// providerAddress expands a provider source to its full registry address:
+// "google" and "hashicorp/google" both become
+// "registry.terraform.io/hashicorp/google".
+func providerAddress(source string) string {
+	source = strings.ToLower(source)
+	switch strings.Count(source, "/") {
+	case 0:
+		return "registry.terraform.io/hashicorp/" + source
+	case 1:
+		return "registry.terraform.io/" + source
+	}
+	return source
+}
+
+// missingLockEntries returns the full addresses of the required providers
+// without a provider block in the parsed lock file.
+func missingLockEntries(lockBlocks []tfBlock, requiredProviders []string) []string {
+	locked := make([]string, 0, len(lockBlocks))
+	for _, b := range blocksOfType(lockBlocks, "provider") {
+		if len(b.Labels) == 1 {
+			locked = append(locked, providerAddress(b.Labels[0]))
+		}
+	}
+	required := make([]string, 0, len(requiredProviders))
+	for _, p := range requiredProviders {
+		required = append(required, providerAddress(p))
+	}
+	return missingFrom(required, locked)
+}
+
+// AssertLockfilePresent fails the test if modulePath has no
+// .terraform.lock.hcl or the lock file has no entry for one of
+// requiredProviders. Providers may be given as "google", "hashicorp/google"
+// or a full registry address.
+func AssertLockfilePresent(t *testing.T, modulePath string, requiredProviders []string) {
+	t.Helper()
+
+	path := filepath.Join(modulePath, lockFileName)
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Module %s has no %s: %v", modulePath, lockFileName, err)
+		return
+	}
+	blocks, err := parseTFFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+	for _, b := range blocksOfType(blocks, "provider") {
+		t.Logf("%s locks %s at %s", path, strings.Join(b.Labels, ""), unquote(b.Attributes["version"]))
+	}
+	for _, p := range missingLockEntries(blocks, requiredProviders) {
+		t.Errorf("%s has no entry for required provider %s", path, p)
+	}
+}
+
+// requiredVersions returns the required_version constraint of every
+// terraform block, normalised to single spaces.
+func requiredVersions(blocks []tfBlock) []string {
+	var constraints []string
+	for _, b := range blocksOfType(blocks, "terraform") {
+		if v, ok := b.Attributes["required_version"]; ok {
+			constraints = append(constraints, strings.Join(strings.Fields(unquote(v)), " "))
+		}
+	}
+	return constraints
+}
+
+// checkRequiredVersion returns an error unless the module declares exactly
+// one required_version and it equals expectedConstraint, ignoring spacing.
+func checkRequiredVersion(blocks []tfBlock, expectedConstraint string) error {
+	constraints := requiredVersions(blocks)
+	want := strings.Join(strings.Fields(expectedConstraint), " ")
+	switch {
+	case len(constraints) == 0:
+		return fmt.Errorf("no terraform required_version, want %q", want)
+	case len(constraints) > 1:
+		return fmt.Errorf("required_version is set %d times: %s", len(constraints), strings.Join(constraints, ", "))
+	case constraints[0] != want:
+		return fmt.Errorf("required_version is %q, want %q", constraints[0], want)
+	}
+	return nil
+}
+
+// AssertRequiredVersion fails the test unless modulePath has a terraform
+// block whose required_version is expectedConstraint, e.g. ">= 1.5.0".
+func AssertRequiredVersion(t *testing.T, modulePath, expectedConstraint string) {
+	t.Helper()
+
+	blocks, err := parseModuleBlocks(modulePath)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", modulePath, err)
+	}
+	t.Logf("Module %s required_version: %v", modulePath, requiredVersions(blocks))
+	if err := checkRequiredVersion(blocks, expectedConstraint); err != nil {
+		t.Errorf("Module %s: %v", modulePath, err)
+	}
+}
+
+// IgnoredUnusedVariables names variables AssertNoUnusedVariables accepts
+// without a reference, e.g. ones kept for interface compatibility.
+var IgnoredUnusedVariables []string
+
+var varReferenceRe = regexp.MustCompile(`\bvar\.([A-Za-z_][A-Za-z0-9_-]*)`)
+
+// unusedVariables returns, in declaration order, the declared variables that
+// no block other than their own declaration references and that are not in
+// ignore.
+func unusedVariables(blocks []tfBlock, ignore []string) []string {
+	referenced := make(map[string]bool)
+	for _, name := range ignore {
+		referenced[name] = true
+	}
+	for _, b := range blocks {
+		if b.Type == "variable" {
+			continue
+		}
+		for _, m := range varReferenceRe.FindAllStringSubmatch(b.Body, -1) {
+			referenced[m[1]] = true
+		}
+	}
+
+	var unused []string
+	for _, v := range blocksOfType(blocks, "variable") {
+		if len(v.Labels) == 1 && !referenced[v.Labels[0]] {
+			unused = append(unused, v.Labels[0])
+		}
+	}
+	return unused
+}
+
+// AssertNoUnusedVariables fails the test listing every variable declared in
+// modulePath that none of its .tf files reference. Variables in
+// IgnoredUnusedVariables are exempt.
+func AssertNoUnusedVariables(t *testing.T, modulePath string) {
+	t.Helper()
+
+	blocks, err := parseModuleBlocks(modulePath)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", modulePath, err)
+	}
+	if unused := unusedVariables(blocks, IgnoredUnusedVariables); len(unused) > 0 {
+		t.Errorf("Module %s declares %d unused variable(s): %s", modulePath, len(unused), strings.Join(unused, ", "))
+	}
+}
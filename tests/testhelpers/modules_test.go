@@ -0,0 +1,270 @@
+package testhelpers
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+func TestDiscoverExamples(t *testing.T) {
+	module := t.TempDir()
+	writeFile(t, filepath.Join(module, "main.tf"), `resource "null_resource" "a" {}`)
+	writeFile(t, filepath.Join(module, "examples", "basic", "main.tf"), `module "m" { source = "../.." }`)
+	writeFile(t, filepath.Join(module, "examples", "broken", "main.tf"), `module "m" {`)
+	writeFile(t, filepath.Join(module, "examples", "README.md"), "docs only")
+	writeFile(t, filepath.Join(module, "examples", "basic", ".terraform", "modules", "m", "main.tf"), "")
+
+	got, err := discoverExamples(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(module, "examples", "basic"), filepath.Join(module, "examples", "broken")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverExamples() = %v, want %v", got, want)
+	}
+
+	if got, err := discoverExamples(t.TempDir()); err != nil || len(got) != 0 {
+		t.Errorf("module without examples: got %v, %v", got, err)
+	}
+}
+
+func TestValidateExampleStubbed(t *testing.T) {
+	dir := t.TempDir()
+	var calls []string
+	orig := terraformRunner
+	terraformRunner = func(_ *testing.T, opts *terraform.Options, args ...string) (string, error) {
+		calls = append(calls, args[0])
+		if args[0] == "validate" && filepath.Base(opts.TerraformDir) == filepath.Base(dir) {
+			return "", errors.New("Error: Unsupported argument")
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { terraformRunner = orig })
+
+	if err := validateExample(t, dir); err == nil {
+		t.Error("expected validation failure")
+	}
+	if !reflect.DeepEqual(calls, []string{"init", "validate"}) {
+		t.Errorf("terraform calls = %v, want init then validate", calls)
+	}
+}
+
+func TestUnpinnedModules(t *testing.T) {
+	module := t.TempDir()
+	writeFile(t, filepath.Join(module, "main.tf"), `module "local" {
+  source = "../vpc"
+}
+
+module "git_pinned" {
+  source = "git::https://github.com/org/modules.git//vpc?ref=v1.2.0"
+}
+
+module "git_unpinned" {
+  source = "github.com/org/modules//nat"
+}
+
+module "registry_pinned" {
+  source  = "terraform-google-modules/network/google"
+  version = "~> 9.0"
+}
+
+module "registry_unpinned" {
+  source = "terraform-google-modules/cloud-nat/google"
+
+  name = "nat-${var.region}"
+  labels = {
+    team = "platform"
+  }
+}
+
+module "registry_submodule_pinned" {
+  source  = "terraform-google-modules/network/google//modules/subnets"
+  version = "~> 9.0"
+}
+
+module "registry_submodule_unpinned" {
+  source = "terraform-google-modules/network/google//modules/subnets"
+}
+
+module "archive" {
+  source = "gcs::https://www.googleapis.com/storage/v1/modules/vpc.zip"
+}
+`)
+
+	blocks, err := parseModuleBlocks(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, unchecked := unpinnedModules(blocks)
+	want := []string{
+		`main.tf:9 module "git_unpinned": git source "github.com/org/modules//nat" has no ?ref= pin`,
+		`main.tf:18 module "registry_unpinned": registry source "terraform-google-modules/cloud-nat/google" has no version`,
+		`main.tf:32 module "registry_submodule_unpinned": registry source "terraform-google-modules/network/google//modules/subnets" has no version`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unpinnedModules() = %q, want %q", got, want)
+	}
+	wantUnchecked := []string{
+		`main.tf:36 module "archive": source "gcs::https://www.googleapis.com/storage/v1/modules/vpc.zip" is not a local, git or registry source`,
+	}
+	if !reflect.DeepEqual(unchecked, wantUnchecked) {
+		t.Errorf("unchecked = %q, want %q", unchecked, wantUnchecked)
+	}
+}
+
+func TestAssertModuleSourcesPinnedLogsUncheckedSources(t *testing.T) {
+	module := t.TempDir()
+	writeFile(t, filepath.Join(module, "main.tf"), `module "archive" {
+  source = "s3::https://s3-eu-west-1.amazonaws.com/modules/vpc.zip"
+}
+`)
+	AssertModuleSourcesPinned(t, module)
+}
+
+const sampleLockFile = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/google" {
+  version     = "5.10.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+    "zh:def",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+  hashes = [
+    "h1:ghi=",
+  ]
+}
+`
+
+func TestMissingLockEntries(t *testing.T) {
+	module := t.TempDir()
+	writeFile(t, filepath.Join(module, lockFileName), sampleLockFile)
+	blocks, err := parseTFFile(filepath.Join(module, lockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := missingLockEntries(blocks, []string{"google", "hashicorp/random"}); len(got) != 0 {
+		t.Errorf("missingLockEntries() = %v, want none", got)
+	}
+	want := []string{"registry.terraform.io/hashicorp/google-beta"}
+	if got := missingLockEntries(blocks, []string{"registry.terraform.io/hashicorp/google", "google-beta"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("missingLockEntries() = %v, want %v", got, want)
+	}
+	if got := missingLockEntries(nil, []string{"google"}); len(got) != 1 {
+		t.Errorf("empty lock file: missingLockEntries() = %v, want google", got)
+	}
+
+	AssertLockfilePresent(t, module, []string{"google", "random"})
+}
+
+func TestCheckRequiredVersion(t *testing.T) {
+	pinned := t.TempDir()
+	writeFile(t, filepath.Join(pinned, "versions.tf"), `terraform {
+  required_version = ">=  1.5.0"
+
+  required_providers {
+    google = {
+      source  = "hashicorp/google"
+      version = "~> 5.0"
+    }
+  }
+}
+`)
+	unpinned := t.TempDir()
+	writeFile(t, filepath.Join(unpinned, "main.tf"), `terraform {
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+`)
+	twice := t.TempDir()
+	writeFile(t, filepath.Join(twice, "a.tf"), "terraform {\n  required_version = \">= 1.5.0\"\n}\n")
+	writeFile(t, filepath.Join(twice, "b.tf"), "terraform {\n  required_version = \">= 1.6.0\"\n}\n")
+
+	cases := []struct {
+		name     string
+		dir      string
+		expected string
+		wantErr  bool
+	}{
+		{"matches ignoring spacing", pinned, ">= 1.5.0", false},
+		{"different constraint", pinned, "~> 1.6", true},
+		{"absent", unpinned, ">= 1.5.0", true},
+		{"set twice", twice, ">= 1.5.0", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocks, err := parseModuleBlocks(tc.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := checkRequiredVersion(blocks, tc.expected); (err != nil) != tc.wantErr {
+				t.Errorf("checkRequiredVersion() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+
+	AssertRequiredVersion(t, pinned, ">= 1.5.0")
+}
+
+func TestUnusedVariables(t *testing.T) {
+	module := t.TempDir()
+	writeFile(t, filepath.Join(module, "variables.tf"), `variable "project_id" {
+  type = string
+}
+
+variable "region" {
+  type = string
+}
+
+variable "labels" {
+  type    = map(string)
+  default = {}
+}
+
+variable "legacy_zone" {
+  type = string
+  validation {
+    condition     = length(var.legacy_zone) > 0
+    error_message = "legacy_zone must not be empty."
+  }
+}
+
+variable "deprecated_flag" {
+  type    = bool
+  default = false
+}
+`)
+	writeFile(t, filepath.Join(module, "main.tf"), `locals {
+  common_labels = merge(var.labels, { managed_by = "terraform" })
+}
+
+resource "google_compute_network" "vpc" {
+  project = var.project_id
+  name    = "vpc-${var.region}"
+}
+`)
+	blocks, err := parseModuleBlocks(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"legacy_zone", "deprecated_flag"}
+	if got := unusedVariables(blocks, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("unusedVariables() = %v, want %v", got, want)
+	}
+	if got := unusedVariables(blocks, want); len(got) != 0 {
+		t.Errorf("unusedVariables() with ignore list = %v, want none", got)
+	}
+}
@@ -0,0 +1,84 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// uptimeCheckConfig mirrors the fields of a Cloud Monitoring uptime check
+// used by the assertions in this file.
+type uptimeCheckConfig struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Period      string `json:"period"`
+	HTTPCheck   *struct {
+		UseSSL bool `json:"useSsl"`
+	} `json:"httpCheck"`
+	TCPCheck *struct {
+		Port int `json:"port"`
+	} `json:"tcpCheck"`
+}
+
+// protocol returns HTTP, HTTPS or TCP, or an empty string for other check
+// types.
+func (c uptimeCheckConfig) protocol() string {
+	switch {
+	case c.HTTPCheck != nil && c.HTTPCheck.UseSSL:
+		return "HTTPS"
+	case c.HTTPCheck != nil:
+		return "HTTP"
+	case c.TCPCheck != nil:
+		return "TCP"
+	}
+	return ""
+}
+
+// findUptimeCheck returns the check named displayName.
+func findUptimeCheck(configs []uptimeCheckConfig, displayName string) (uptimeCheckConfig, bool) {
+	for _, c := range configs {
+		if c.DisplayName == displayName {
+			return c, true
+		}
+	}
+	return uptimeCheckConfig{}, false
+}
+
+// checkUptimeCheck returns the differences between the check's protocol and
+// period and the expected ones.
+func checkUptimeCheck(c uptimeCheckConfig, expectProtocol string, expectPeriod time.Duration) []string {
+	var problems []string
+	if actual := c.protocol(); !strings.EqualFold(actual, expectProtocol) {
+		problems = append(problems, fmt.Sprintf("protocol is %q, want %s", actual, expectProtocol))
+	}
+	period, err := time.ParseDuration(c.Period)
+	switch {
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("period %q is not a duration", c.Period))
+	case period != expectPeriod:
+		problems = append(problems, fmt.Sprintf("period is %s, want %s", period, expectPeriod))
+	}
+	return problems
+}
+
+// AssertUptimeCheck fails the test unless an uptime check named displayName
+// exists and checks over expectProtocol (HTTP, HTTPS or TCP) every
+// expectPeriod.
+func AssertUptimeCheck(t *testing.T, projectID, displayName string, expectProtocol string, expectPeriod time.Duration) {
+	t.Helper()
+
+	var configs []uptimeCheckConfig
+	if err := gcloudJSON(&configs, "monitoring", "uptime", "list-configs", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list uptime checks in %s: %v", projectID, err)
+	}
+	check, ok := findUptimeCheck(configs, displayName)
+	if !ok {
+		t.Errorf("Uptime check %q not found in project %s", displayName, projectID)
+		return
+	}
+	t.Logf("Uptime check %q protocol=%s period=%s", displayName, check.protocol(), check.Period)
+	for _, p := range checkUptimeCheck(check, expectProtocol, expectPeriod) {
+		t.Errorf("Uptime check %q: %s", displayName, p)
+	}
+}
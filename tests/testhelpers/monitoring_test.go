@@ -0,0 +1,46 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCheckUptimeCheck(t *testing.T) {
+	const raw = `[
+		{"displayName":"web-https","period":"60s","httpCheck":{"useSsl":true,"path":"/healthz"}},
+		{"displayName":"web-http","period":"300s","httpCheck":{"path":"/"}},
+		{"displayName":"db-tcp","period":"900s","tcpCheck":{"port":5432}}]`
+	var configs []uptimeCheckConfig
+	if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findUptimeCheck(configs, "missing"); ok {
+		t.Error("findUptimeCheck() found a check that does not exist")
+	}
+
+	cases := []struct {
+		name         string
+		displayName  string
+		protocol     string
+		period       time.Duration
+		wantProblems int
+	}{
+		{"https matches", "web-https", "HTTPS", time.Minute, 0},
+		{"protocol case-insensitive", "web-http", "http", 5 * time.Minute, 0},
+		{"tcp matches", "db-tcp", "TCP", 15 * time.Minute, 0},
+		{"https expected on http", "web-http", "HTTPS", 5 * time.Minute, 1},
+		{"both differ", "db-tcp", "HTTPS", time.Minute, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			check, ok := findUptimeCheck(configs, tc.displayName)
+			if !ok {
+				t.Fatalf("check %s not found", tc.displayName)
+			}
+			if got := checkUptimeCheck(check, tc.protocol, tc.period); len(got) != tc.wantProblems {
+				t.Errorf("checkUptimeCheck() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
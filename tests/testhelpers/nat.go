@@ -0,0 +1,79 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// cloudRouter mirrors the fields of a Cloud Router used by the assertions in
+// this file.
+type cloudRouter struct {
+	Name string     `json:"name"`
+	Nats []cloudNAT `json:"nats"`
+}
+
+// cloudNAT is a Cloud NAT gateway configured on a router.
+type cloudNAT struct {
+	Name                string   `json:"name"`
+	NatIPAllocateOption string   `json:"natIpAllocateOption"`
+	NatIPs              []string `json:"natIps"`
+}
+
+// findNAT returns the NAT gateway named nat and the router it belongs to.
+func findNAT(routers []cloudRouter, nat string) (cloudNAT, string, bool) {
+	for _, r := range routers {
+		for _, n := range r.Nats {
+			if n.Name == nat {
+				return n, r.Name, true
+			}
+		}
+	}
+	return cloudNAT{}, "", false
+}
+
+// checkNATIPCount returns an error unless the gateway allocates at least
+// minIPs manual NAT IPs. A minIPs of zero or less instead expects AUTO_ONLY
+// allocation.
+func checkNATIPCount(n cloudNAT, minIPs int) error {
+	if minIPs <= 0 {
+		if n.NatIPAllocateOption != "AUTO_ONLY" {
+			return fmt.Errorf("NAT IP allocation is %s, want AUTO_ONLY", n.NatIPAllocateOption)
+		}
+		return nil
+	}
+	if n.NatIPAllocateOption != "MANUAL_ONLY" {
+		return fmt.Errorf("NAT IP allocation is %s, want MANUAL_ONLY with at least %d IPs", n.NatIPAllocateOption, minIPs)
+	}
+	if len(n.NatIPs) < minIPs {
+		return fmt.Errorf("%d NAT IP(s) allocated, want at least %d", len(n.NatIPs), minIPs)
+	}
+	return nil
+}
+
+// AssertNATIPCount fails the test unless the Cloud NAT gateway in region
+// has at least minIPs manually allocated IPs, or uses AUTO_ONLY allocation
+// when minIPs is zero. The gateway is found by name across the region's
+// routers.
+func AssertNATIPCount(t *testing.T, projectID, region, nat string, minIPs int) {
+	t.Helper()
+
+	var routers []cloudRouter
+	if err := gcloudJSON(&routers, "compute", "routers", "list", "--regions", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to list routers in %s: %v", region, err)
+	}
+	n, router, ok := findNAT(routers, nat)
+	if !ok {
+		t.Errorf("Cloud NAT %s not found on any router in %s", nat, region)
+		return
+	}
+
+	ips := make([]string, 0, len(n.NatIPs))
+	for _, ip := range n.NatIPs {
+		ips = append(ips, lastSegment(ip))
+	}
+	t.Logf("Cloud NAT %s (router %s) allocation=%s IPs=[%s]", nat, router, n.NatIPAllocateOption, strings.Join(ips, ", "))
+	if err := checkNATIPCount(n, minIPs); err != nil {
+		t.Errorf("Cloud NAT %s: %v", nat, err)
+	}
+}
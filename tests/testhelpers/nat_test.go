@@ -0,0 +1,45 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckNATIPCount(t *testing.T) {
+	const raw = `[
+		{"name":"edge-router","nats":[{"name":"egress-nat","natIpAllocateOption":"MANUAL_ONLY","natIps":[
+			"https://www.googleapis.com/compute/v1/projects/p/regions/europe-west1/addresses/nat-1",
+			"https://www.googleapis.com/compute/v1/projects/p/regions/europe-west1/addresses/nat-2"]}]},
+		{"name":"dev-router","nats":[{"name":"dev-nat","natIpAllocateOption":"AUTO_ONLY"}]}]`
+	var routers []cloudRouter
+	if err := json.Unmarshal([]byte(raw), &routers); err != nil {
+		t.Fatal(err)
+	}
+	if _, router, ok := findNAT(routers, "dev-nat"); !ok || router != "dev-router" {
+		t.Errorf("findNAT(dev-nat) = %s, %t, want dev-router", router, ok)
+	}
+	if _, _, ok := findNAT(routers, "missing"); ok {
+		t.Error("findNAT() found a gateway that does not exist")
+	}
+
+	cases := []struct {
+		name    string
+		nat     string
+		minIPs  int
+		wantErr bool
+	}{
+		{"enough manual IPs", "egress-nat", 2, false},
+		{"too few manual IPs", "egress-nat", 3, true},
+		{"auto expected", "dev-nat", 0, false},
+		{"manual expected on auto", "dev-nat", 1, true},
+		{"auto expected on manual", "egress-nat", 0, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, _, _ := findNAT(routers, tc.nat)
+			if err := checkNATIPCount(n, tc.minIPs); (err != nil) != tc.wantErr {
+				t.Errorf("checkNATIPCount() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
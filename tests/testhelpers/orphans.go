@@ -0,0 +1,241 @@
+package testhelpers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cleanupOrphansEnv makes FindOrphanedTestResources delete what it finds
+// when set to a true value:
+//
+//	TEST_CLEANUP_ORPHANS=1 go test ./... -run TestOrphans
+const cleanupOrphansEnv = "TEST_CLEANUP_ORPHANS"
+
+// cleanupRequested reports whether TEST_CLEANUP_ORPHANS is set to a true
+// value. It is read on each call so tests can set it with t.Setenv.
+func cleanupRequested() bool {
+	cleanup, _ := strconv.ParseBool(os.Getenv(cleanupOrphansEnv))
+	return cleanup
+}
+
+// ResourceRef identifies a project resource created by a test run.
+type ResourceRef struct {
+	Kind     string // "network", "instance" or "bucket"
+	Name     string
+	Location string // zone for instances; empty for global resources
+	Created  time.Time
+}
+
+func (r ResourceRef) String() string {
+	if r.Location != "" {
+		return fmt.Sprintf("%s %s (%s)", r.Kind, r.Name, r.Location)
+	}
+	return r.Kind + " " + r.Name
+}
+
+// timestampLayouts covers the Compute API (RFC 3339 with milliseconds) and
+// gcloud storage (numeric zone without colon) creation timestamps.
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05.999999-0700"}
+
+func parseCreationTime(s string) time.Time {
+	for _, layout := range timestampLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
+
+// listTestResources lists the networks, instances and buckets in a project.
+func listTestResources(t *testing.T, projectID string) []ResourceRef {
+	t.Helper()
+
+	var networks, instances []struct {
+		Name              string `json:"name"`
+		Zone              string `json:"zone"`
+		CreationTimestamp string `json:"creationTimestamp"`
+	}
+	var buckets []struct {
+		Name         string `json:"name"`
+		CreationTime string `json:"creation_time"`
+	}
+	if err := gcloudJSON(&networks, "compute", "networks", "list", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list networks in %s: %v", projectID, err)
+	}
+	if err := gcloudJSON(&instances, "compute", "instances", "list", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list instances in %s: %v", projectID, err)
+	}
+	if err := gcloudJSON(&buckets, "storage", "buckets", "list", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list buckets in %s: %v", projectID, err)
+	}
+
+	var refs []ResourceRef
+	for _, n := range networks {
+		refs = append(refs, ResourceRef{Kind: "network", Name: n.Name, Created: parseCreationTime(n.CreationTimestamp)})
+	}
+	for _, i := range instances {
+		refs = append(refs, ResourceRef{Kind: "instance", Name: i.Name, Location: lastSegment(i.Zone), Created: parseCreationTime(i.CreationTimestamp)})
+	}
+	for _, b := range buckets {
+		refs = append(refs, ResourceRef{Kind: "bucket", Name: b.Name, Created: parseCreationTime(b.CreationTime)})
+	}
+	return refs
+}
+
+// withPrefix returns the resources whose names start with prefix.
+func withPrefix(refs []ResourceRef, prefix string) []ResourceRef {
+	var matched []ResourceRef
+	for _, r := range refs {
+		if strings.HasPrefix(r.Name, prefix) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// filterOrphans returns the resources named with prefix that were created
+// more than olderThan before now, and separately those named with prefix
+// whose creation time is unknown. The latter are never considered orphans,
+// so they are never deleted.
+func filterOrphans(refs []ResourceRef, prefix string, olderThan time.Duration, now time.Time) (orphans, unknownAge []ResourceRef) {
+	for _, r := range withPrefix(refs, prefix) {
+		switch {
+		case r.Created.IsZero():
+			unknownAge = append(unknownAge, r)
+		case now.Sub(r.Created) >= olderThan:
+			orphans = append(orphans, r)
+		}
+	}
+	return orphans, unknownAge
+}
+
+// deleteOrder deletes instances before buckets and networks, since a
+// network cannot be removed while instances still use it.
+var deleteOrder = map[string]int{"instance": 0, "bucket": 1, "network": 2}
+
+// networkAttachment is a subnet, firewall rule or router and the network it
+// belongs to.
+type networkAttachment struct {
+	Name    string `json:"name"`
+	Network string `json:"network"`
+}
+
+// attachedTo returns the names of the attachments on network, prefixed
+// with kind.
+func attachedTo(network, kind string, attachments []networkAttachment) []string {
+	var names []string
+	for _, a := range attachments {
+		if lastSegment(a.Network) == network {
+			names = append(names, kind+" "+a.Name)
+		}
+	}
+	return names
+}
+
+// networkDependents lists the subnets, firewall rules and routers that keep
+// network from being deleted. Subnets of auto mode networks are included,
+// so such networks are always left in place.
+func networkDependents(projectID, network string) ([]string, error) {
+	var dependents []string
+	for _, kind := range []struct {
+		name string
+		args []string
+	}{
+		{"subnet", []string{"compute", "networks", "subnets", "list"}},
+		{"firewall rule", []string{"compute", "firewall-rules", "list"}},
+		{"router", []string{"compute", "routers", "list"}},
+	} {
+		var attachments []networkAttachment
+		if err := gcloudJSON(&attachments, append(kind.args, "--project", projectID)...); err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, attachedTo(network, kind.name, attachments)...)
+	}
+	return dependents, nil
+}
+
+func deleteResource(projectID string, r ResourceRef) error {
+	var args []string
+	switch r.Kind {
+	case "instance":
+		args = []string{"compute", "instances", "delete", r.Name, "--zone", r.Location}
+	case "network":
+		args = []string{"compute", "networks", "delete", r.Name}
+	case "bucket":
+		args = []string{"storage", "rm", "--recursive", "gs://" + r.Name}
+	default:
+		return fmt.Errorf("don't know how to delete %s", r.Kind)
+	}
+	_, err := commandRunner("gcloud", append(args, "--project", projectID, "--quiet")...)
+	return err
+}
+
+// FindOrphanedTestResources returns networks, instances and buckets named
+// with prefix that are older than olderThan, which a failed cleanup most
+// likely left behind. With TEST_CLEANUP_ORPHANS=1 they are also deleted.
+// Resources whose creation time can't be determined are reported but never
+// returned or deleted, and an empty prefix is rejected since it would match
+// every resource in the project.
+func FindOrphanedTestResources(t *testing.T, projectID, prefix string, olderThan time.Duration) []ResourceRef {
+	t.Helper()
+
+	if prefix == "" {
+		t.Fatal("FindOrphanedTestResources requires a non-empty resource name prefix")
+	}
+	orphans, unknownAge := filterOrphans(listTestResources(t, projectID), prefix, olderThan, now())
+	for _, r := range unknownAge {
+		t.Logf("Skipping %s: creation time unknown, check it manually", r)
+	}
+	for _, o := range orphans {
+		t.Logf("Orphaned test resource: %s created %s", o, o.Created.Format(time.RFC3339))
+	}
+	if !cleanupRequested() || len(orphans) == 0 {
+		return orphans
+	}
+
+	sorted := append([]ResourceRef(nil), orphans...)
+	sort.SliceStable(sorted, func(i, j int) bool { return deleteOrder[sorted[i].Kind] < deleteOrder[sorted[j].Kind] })
+	for _, o := range sorted {
+		// Deleting a network that still has subnets, firewall rules or
+		// routers fails, and those may not carry the test prefix, so the
+		// network is left for manual cleanup rather than removing them.
+		if o.Kind == "network" {
+			dependents, err := networkDependents(projectID, o.Name)
+			if err != nil {
+				t.Errorf("Failed to list resources attached to %s: %v", o, err)
+				continue
+			}
+			if len(dependents) > 0 {
+				t.Logf("Leaving %s for manual cleanup: it still has %s", o, strings.Join(dependents, ", "))
+				continue
+			}
+		}
+		if err := deleteResource(projectID, o); err != nil {
+			t.Errorf("Failed to delete %s: %v", o, err)
+		} else {
+			t.Logf("Deleted %s", o)
+		}
+	}
+	return orphans
+}
+
+// AssertCleanDestroy fails the test listing every network, instance and
+// bucket named with prefix that still exists. Call it after terraform
+// destroy to catch resources the destroy left behind.
+func AssertCleanDestroy(t *testing.T, projectID, prefix string) {
+	t.Helper()
+
+	if prefix == "" {
+		t.Fatal("AssertCleanDestroy requires a non-empty resource name prefix")
+	}
+	residuals := withPrefix(listTestResources(t, projectID), prefix)
+	t.Logf("Found %d resource(s) named %s* in %s after destroy", len(residuals), prefix, projectID)
+	for _, r := range residuals {
+		t.Errorf("Residual resource after destroy: %s", r)
+	}
+}
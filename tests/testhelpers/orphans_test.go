@@ -0,0 +1,117 @@
+package testhelpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterOrphans(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	refs := []ResourceRef{
+		{Kind: "network", Name: "tt-vpc-old", Created: now.Add(-48 * time.Hour)},
+		{Kind: "instance", Name: "tt-vm-fresh", Location: "europe-west1-b", Created: now.Add(-10 * time.Minute)},
+		{Kind: "bucket", Name: "tt-bucket-unknown-age"},
+		{Kind: "network", Name: "prod-vpc", Created: now.Add(-720 * time.Hour)},
+	}
+
+	orphans, unknownAge := filterOrphans(refs, "tt-", 6*time.Hour, now)
+	if want := []ResourceRef{refs[0]}; !reflect.DeepEqual(orphans, want) {
+		t.Errorf("filterOrphans() orphans = %v, want %v", orphans, want)
+	}
+	if want := []ResourceRef{refs[2]}; !reflect.DeepEqual(unknownAge, want) {
+		t.Errorf("filterOrphans() unknown age = %v, want %v", unknownAge, want)
+	}
+}
+
+func TestAttachedTo(t *testing.T) {
+	attachments := []networkAttachment{
+		{Name: "tt-allow-ssh", Network: "https://www.googleapis.com/compute/v1/projects/p/global/networks/tt-vpc"},
+		{Name: "default-allow-icmp", Network: "https://www.googleapis.com/compute/v1/projects/p/global/networks/default"},
+		{Name: "tt-vpc-egress", Network: "projects/p/global/networks/tt-vpc-2"},
+	}
+	want := []string{"firewall rule tt-allow-ssh"}
+	if got := attachedTo("tt-vpc", "firewall rule", attachments); !reflect.DeepEqual(got, want) {
+		t.Errorf("attachedTo() = %v, want %v", got, want)
+	}
+}
+
+func TestFindOrphanedTestResourcesCleanup(t *testing.T) {
+	t.Setenv(cleanupOrphansEnv, "1")
+	orig, origNow := commandRunner, now
+	t.Cleanup(func() { commandRunner, now = orig, origNow })
+	now = func() time.Time { return time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) }
+
+	const old = "2026-03-01T00:00:00.000-00:00"
+	responses := map[string]string{
+		"compute networks list":       `[{"name":"tt-vpc","creationTimestamp":"` + old + `"},{"name":"tt-bare-vpc","creationTimestamp":"` + old + `"}]`,
+		"compute instances list":      `[{"name":"tt-vm","zone":"zones/europe-west1-b"}]`,
+		"storage buckets list":        `[{"name":"tt-logs","creation_time":"` + old + `"},{"name":"prod-logs","creation_time":"` + old + `"}]`,
+		"compute firewall-rules list": `[{"name":"tt-allow-ssh","network":"projects/p/global/networks/tt-vpc"}]`,
+	}
+	var deleted []string
+	commandRunner = func(_ string, args ...string) ([]byte, error) {
+		cmd := strings.Join(args[:3], " ")
+		if args[1] == "networks" && args[2] == "subnets" {
+			cmd = strings.Join(args[:4], " ")
+		}
+		switch {
+		case strings.HasSuffix(cmd, " list"):
+			if out, ok := responses[cmd]; ok {
+				return []byte(out), nil
+			}
+			return []byte(`[]`), nil
+		case strings.Contains(cmd, "delete") || strings.HasPrefix(cmd, "storage rm"):
+			deleted = append(deleted, args[len(args)-4])
+			return nil, nil
+		}
+		t.Fatalf("unexpected gcloud call: %v", args)
+		return nil, nil
+	}
+
+	var orphans []string
+	for _, o := range FindOrphanedTestResources(t, "p", "tt-", time.Hour) {
+		orphans = append(orphans, o.String())
+	}
+	if want := []string{"network tt-vpc", "network tt-bare-vpc", "bucket tt-logs"}; !reflect.DeepEqual(orphans, want) {
+		t.Errorf("orphans = %v, want %v", orphans, want)
+	}
+	// tt-vm has no creation time and tt-vpc still has a firewall rule, so
+	// neither is deleted.
+	if want := []string{"gs://tt-logs", "tt-bare-vpc"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted = %v, want %v", deleted, want)
+	}
+}
+
+func TestParseCreationTime(t *testing.T) {
+	want := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+	for _, s := range []string{"2026-03-01T10:00:00.000-07:00", "2026-03-01T17:00:00+0000"} {
+		if got := parseCreationTime(s); !got.Equal(want) {
+			t.Errorf("parseCreationTime(%q) = %s, want %s", s, got, want)
+		}
+	}
+	if got := parseCreationTime("garbage"); !got.IsZero() {
+		t.Errorf("parseCreationTime(garbage) = %s, want zero", got)
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	refs := []ResourceRef{
+		{Kind: "network", Name: "tt-vpc"},
+		{Kind: "instance", Name: "web-1", Location: "europe-west1-b"},
+		{Kind: "bucket", Name: "tt-assets"},
+	}
+	want := []ResourceRef{refs[0], refs[2]}
+	if got := withPrefix(refs, "tt-"); !reflect.DeepEqual(got, want) {
+		t.Errorf("withPrefix() = %v, want %v", got, want)
+	}
+	if got := withPrefix(refs, "none-"); len(got) != 0 {
+		t.Errorf("withPrefix(none-) = %v, want none", got)
+	}
+}
+
+func TestAssertCleanDestroyNoResiduals(t *testing.T) {
+	stubGcloud(t, `[]`)
+	AssertCleanDestroy(t, "test-project", "tt-")
+}
@@ -0,0 +1,173 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// outputReader returns the named terraform output as raw JSON. Unit tests
+// replace it to serve canned outputs.
+var outputReader = func(t *testing.T, opts *terraform.Options, name string) (string, error) {
+	return terraform.OutputJsonE(t, opts, name)
+}
+
+// normalizeJSON round-trips v through JSON so Go values and decoded outputs
+// compare equal (e.g. int 3 and float64 3, []string and []interface{}).
+func normalizeJSON(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// compareOutput returns an error describing how the raw JSON output differs
+// from expected.
+func compareOutput(name, rawOutput string, expected interface{}) error {
+	var actual interface{}
+	if err := json.Unmarshal([]byte(rawOutput), &actual); err != nil {
+		return fmt.Errorf("output %q is not valid JSON: %w", name, err)
+	}
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		return fmt.Errorf("cannot encode expected value for output %q: %w", name, err)
+	}
+	if !reflect.DeepEqual(actual, want) {
+		return fmt.Errorf("output %q = %s, want %v", name, rawOutput, expected)
+	}
+	return nil
+}
+
+// outputText returns the output as plain text: the string itself for string
+// outputs, the raw JSON otherwise.
+func outputText(rawOutput string) string {
+	var s string
+	if err := json.Unmarshal([]byte(rawOutput), &s); err == nil {
+		return s
+	}
+	return rawOutput
+}
+
+// matchOutput returns an error if the output text does not match pattern.
+func matchOutput(name, rawOutput string, pattern *regexp.Regexp) error {
+	if text := outputText(rawOutput); !pattern.MatchString(text) {
+		return fmt.Errorf("output %q = %q does not match %s", name, text, pattern)
+	}
+	return nil
+}
+
+// AssertOutputEquals fails the test if the terraform output does not equal
+// expected. Non-string outputs are compared structurally.
+func AssertOutputEquals(t *testing.T, opts *terraform.Options, name string, expected interface{}) {
+	t.Helper()
+
+	raw, err := outputReader(t, opts, name)
+	if err != nil {
+		t.Fatalf("Failed to read output %q: %v", name, err)
+	}
+	if err := compareOutput(name, raw, expected); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertOutputMatches fails the test if the terraform output does not match
+// pattern.
+func AssertOutputMatches(t *testing.T, opts *terraform.Options, name string, pattern *regexp.Regexp) {
+	t.Helper()
+
+	raw, err := outputReader(t, opts, name)
+	if err != nil {
+		t.Fatalf("Failed to read output %q: %v", name, err)
+	}
+	if err := matchOutput(name, raw, pattern); err != nil {
+		t.Error(err)
+	}
+}
+
+// isEmptyOutput reports whether a raw JSON output is null, an empty string,
+// or an empty list or map.
+func isEmptyOutput(rawOutput string) bool {
+	var v interface{}
+	if err := json.Unmarshal([]byte(rawOutput), &v); err != nil {
+		return strings.TrimSpace(rawOutput) == ""
+	}
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+// AssertOutputsNonEmpty fails the test listing every named output that is
+// missing, null or empty.
+func AssertOutputsNonEmpty(t *testing.T, opts *terraform.Options, names []string) {
+	t.Helper()
+
+	var empty []string
+	for _, name := range names {
+		raw, err := outputReader(t, opts, name)
+		if err != nil {
+			t.Logf("Failed to read output %q: %v", name, err)
+			empty = append(empty, name)
+			continue
+		}
+		if isEmptyOutput(raw) {
+			empty = append(empty, name)
+		}
+	}
+	if len(empty) > 0 {
+		t.Errorf("Outputs are empty or null: %s", strings.Join(empty, ", "))
+	}
+}
+
+// pollOutput reads the named output every interval until it is non-empty or
+// timeout elapses. It returns the raw output and the number of reads.
+func pollOutput(t *testing.T, opts *terraform.Options, name string, timeout, interval time.Duration) (string, int, error) {
+	deadline := now().Add(timeout)
+	for attempt := 1; ; attempt++ {
+		raw, err := outputReader(t, opts, name)
+		if err == nil && !isEmptyOutput(raw) {
+			return raw, attempt, nil
+		}
+		if !now().Add(interval).Before(deadline) {
+			if err != nil {
+				return "", attempt, fmt.Errorf("output %q not available after %s: %w", name, timeout, err)
+			}
+			return "", attempt, fmt.Errorf("output %q still empty after %s", name, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// OutputEventually reads the named terraform output, retrying every
+// Config.DefaultPollInterval until it is non-empty, and returns it as text
+// (see AssertOutputMatches). The test fails once timeout elapses; a zero
+// timeout uses Config.DefaultTimeout.
+func OutputEventually(t *testing.T, opts *terraform.Options, name string, timeout time.Duration) string {
+	t.Helper()
+
+	timeout = Config.timeout(timeout)
+	raw, attempts, err := pollOutput(t, opts, name, timeout, Config.pollInterval(0))
+	if err != nil {
+		t.Fatalf("%v (%d attempts)", err, attempts)
+	}
+	if attempts > 1 {
+		t.Logf("Output %q populated after %d attempts", name, attempts)
+	}
+	return outputText(raw)
+}
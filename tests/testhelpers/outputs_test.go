@@ -0,0 +1,138 @@
+package testhelpers
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// stubOutputs makes outputReader serve the given raw JSON outputs.
+func stubOutputs(t *testing.T, outputs map[string]string) {
+	t.Helper()
+	orig := outputReader
+	outputReader = func(_ *testing.T, _ *terraform.Options, name string) (string, error) {
+		raw, ok := outputs[name]
+		if !ok {
+			return "", fmt.Errorf("output %q not found", name)
+		}
+		return raw, nil
+	}
+	t.Cleanup(func() { outputReader = orig })
+}
+
+func TestCompareOutput(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected interface{}
+		wantErr  bool
+	}{
+		{"string", `"REGIONAL"`, "REGIONAL", false},
+		{"string mismatch", `"GLOBAL"`, "REGIONAL", true},
+		{"number", `16`, 16, false},
+		{"list", `["10.0.0.0/24","10.0.1.0/24"]`, []string{"10.0.0.0/24", "10.0.1.0/24"}, false},
+		{"list order", `["10.0.1.0/24","10.0.0.0/24"]`, []string{"10.0.0.0/24", "10.0.1.0/24"}, true},
+		{"map", `{"env":"dev"}`, map[string]string{"env": "dev"}, false},
+		{"invalid json", `not json`, "x", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := compareOutput("out", tc.raw, tc.expected); (err != nil) != tc.wantErr {
+				t.Errorf("compareOutput() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchOutput(t *testing.T) {
+	cidr := regexp.MustCompile(`^10\.\d+\.\d+\.\d+/\d+$`)
+	if err := matchOutput("cidr", `"10.0.0.0/16"`, cidr); err != nil {
+		t.Error(err)
+	}
+	if err := matchOutput("cidr", `"192.168.0.0/16"`, cidr); err == nil {
+		t.Error("expected mismatch for 192.168.0.0/16")
+	}
+}
+
+func TestAssertOutputHelpers(t *testing.T) {
+	stubOutputs(t, map[string]string{
+		"routing_mode": `"REGIONAL"`,
+		"network_name": `"test-vpc-abc123"`,
+	})
+	opts := &terraform.Options{}
+
+	AssertOutputEquals(t, opts, "routing_mode", "REGIONAL")
+	AssertOutputMatches(t, opts, "network_name", regexp.MustCompile(`^test-vpc-`))
+}
+
+func TestIsEmptyOutput(t *testing.T) {
+	cases := map[string]bool{
+		`null`:           true,
+		`""`:             true,
+		`[]`:             true,
+		`{}`:             true,
+		``:               true,
+		`"vpc-main"`:     false,
+		`0`:              false,
+		`false`:          false,
+		`["10.0.0.0/8"]`: false,
+	}
+	for raw, want := range cases {
+		if got := isEmptyOutput(raw); got != want {
+			t.Errorf("isEmptyOutput(%q) = %t, want %t", raw, got, want)
+		}
+	}
+}
+
+func TestAssertOutputsNonEmptyStubbed(t *testing.T) {
+	stubOutputs(t, map[string]string{
+		"network_name":      `"test-vpc"`,
+		"network_self_link": `"https://www.googleapis.com/compute/v1/projects/p/global/networks/test-vpc"`,
+	})
+	AssertOutputsNonEmpty(t, &terraform.Options{}, []string{"network_name", "network_self_link"})
+}
+
+// stubOutputSequence makes outputReader serve the given raw JSON values in
+// turn, repeating the last one.
+func stubOutputSequence(t *testing.T, values ...string) *int {
+	t.Helper()
+	orig := outputReader
+	reads := 0
+	outputReader = func(*testing.T, *terraform.Options, string) (string, error) {
+		reads++
+		if reads > len(values) {
+			return values[len(values)-1], nil
+		}
+		return values[reads-1], nil
+	}
+	t.Cleanup(func() { outputReader = orig })
+	return &reads
+}
+
+func TestOutputEventuallyRetriesUntilPopulated(t *testing.T) {
+	orig := Config
+	t.Cleanup(func() { Config = orig })
+	Config.DefaultPollInterval = time.Millisecond
+
+	reads := stubOutputSequence(t, `null`, `""`, `"10.0.0.5"`)
+	if got := OutputEventually(t, &terraform.Options{}, "lb_ip", time.Second); got != "10.0.0.5" {
+		t.Errorf("OutputEventually() = %q, want 10.0.0.5", got)
+	}
+	if *reads != 3 {
+		t.Errorf("read output %d times, want 3", *reads)
+	}
+}
+
+func TestPollOutputTimesOut(t *testing.T) {
+	reads := stubOutputSequence(t, `null`)
+	_, attempts, err := pollOutput(t, &terraform.Options{}, "lb_ip", 20*time.Millisecond, 5*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if attempts < 2 || attempts != *reads {
+		t.Errorf("attempts = %d, reads = %d, want several matching reads", attempts, *reads)
+	}
+}
@@ -0,0 +1,187 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// tfPlan is the subset of `terraform show -json PLANFILE` output the helpers
+// use.
+type tfPlan struct {
+	// ResourceDrift lists changes terraform detected while refreshing, i.e.
+	// objects modified outside Terraform since the state was recorded.
+	ResourceDrift []tfResourceChange `json:"resource_drift"`
+	// ResourceChanges lists the actions needed to reconcile the
+	// configuration with the refreshed state.
+	ResourceChanges []tfResourceChange        `json:"resource_changes"`
+	OutputChanges   map[string]tfOutputChange `json:"output_changes"`
+	PlannedValues   *struct {
+		RootModule tfModule `json:"root_module"`
+	} `json:"planned_values"`
+}
+
+// tfResourceChange is an entry of resource_drift or resource_changes.
+type tfResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Change  struct {
+		Actions []string               `json:"actions"`
+		Before  map[string]interface{} `json:"before"`
+		After   map[string]interface{} `json:"after"`
+		// AfterSensitive mirrors After with true at every sensitive value,
+		// or is true when the whole object is sensitive.
+		AfterSensitive interface{} `json:"after_sensitive"`
+	} `json:"change"`
+}
+
+// tfOutputChange is an entry of output_changes.
+type tfOutputChange struct {
+	Actions        []string    `json:"actions"`
+	After          interface{} `json:"after"`
+	AfterSensitive interface{} `json:"after_sensitive"`
+}
+
+func parsePlanJSON(raw []byte) (tfPlan, error) {
+	var plan tfPlan
+	if err := json.Unmarshal(raw, &plan); err != nil {
+		return tfPlan{}, fmt.Errorf("decoding terraform plan JSON: %w", err)
+	}
+	return plan, nil
+}
+
+// isNoOp reports whether the change has no effect.
+func (c tfResourceChange) isNoOp() bool {
+	for _, a := range c.Change.Actions {
+		if a != "no-op" && a != "read" {
+			return false
+		}
+	}
+	return true
+}
+
+// changedAttributes returns the sorted top-level attributes whose values
+// differ between Before and After.
+func (c tfResourceChange) changedAttributes() []string {
+	keys := make(map[string]bool)
+	for k := range c.Change.Before {
+		keys[k] = true
+	}
+	for k := range c.Change.After {
+		keys[k] = true
+	}
+
+	var changed []string
+	for k := range keys {
+		before, _ := json.Marshal(c.Change.Before[k])
+		after, _ := json.Marshal(c.Change.After[k])
+		if string(before) != string(after) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// outOfBandDrift describes each managed resource in the plan's
+// resource_drift that was changed or deleted outside Terraform.
+func outOfBandDrift(plan tfPlan) []string {
+	var drift []string
+	for _, c := range plan.ResourceDrift {
+		if c.Mode == "data" || c.isNoOp() {
+			continue
+		}
+		if c.Change.After == nil {
+			drift = append(drift, fmt.Sprintf("%s was deleted outside Terraform", c.Address))
+		} else {
+			drift = append(drift, fmt.Sprintf("%s was changed outside Terraform (%s)", c.Address, strings.Join(c.changedAttributes(), ", ")))
+		}
+	}
+	return drift
+}
+
+// planArgs returns the arguments for an unlocked plan of opts saved to
+// planFile. FormatArgs appends -lock and -out from the options after any raw
+// flags, so both are set on a copy of opts rather than passed directly.
+func planArgs(opts *terraform.Options, planFile string, planFlags ...string) []string {
+	planOpts := *opts
+	planOpts.Lock = false
+	planOpts.PlanFilePath = planFile
+	return terraform.FormatArgs(&planOpts, append([]string{"plan", "-input=false"}, planFlags...)...)
+}
+
+// showPlan saves a plan for opts with the given extra plan flags and
+// returns it parsed from `terraform show -json`.
+func showPlan(t *testing.T, opts *terraform.Options, planFlags ...string) tfPlan {
+	t.Helper()
+
+	planFile := filepath.Join(t.TempDir(), "test.tfplan")
+	if _, err := terraformRunner(t, opts, planArgs(opts, planFile, planFlags...)...); err != nil {
+		t.Fatalf("terraform plan failed: %v", err)
+	}
+	out, err := terraformStdoutRunner(t, opts, "show", "-json", "-no-color", planFile)
+	if err != nil {
+		t.Fatalf("terraform show -json %s failed: %v", planFile, err)
+	}
+	plan, err := parsePlanJSON([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+// DetectOutOfBandDrift runs a refresh-only plan for opts and fails the test
+// for every managed resource whose real state drifted from the recorded
+// state. Differences between the configuration and the state are not
+// reported. The drift descriptions are returned.
+func DetectOutOfBandDrift(t *testing.T, opts *terraform.Options) []string {
+	t.Helper()
+
+	drift := outOfBandDrift(showPlan(t, opts, "-refresh-only"))
+	if len(drift) == 0 {
+		t.Logf("No out-of-band drift in %s", opts.TerraformDir)
+	}
+	for _, d := range drift {
+		t.Errorf("Out-of-band drift in %s: %s", opts.TerraformDir, d)
+	}
+	return drift
+}
+
+// plannedAddresses returns the sorted addresses of the managed resources
+// the plan leaves in place, including those in child modules.
+func plannedAddresses(plan tfPlan) []string {
+	var addresses []string
+	if plan.PlannedValues == nil {
+		return addresses
+	}
+	plan.PlannedValues.RootModule.walk(func(r tfResource) {
+		if r.Mode != "data" {
+			addresses = append(addresses, r.Address)
+		}
+	})
+	sort.Strings(addresses)
+	return addresses
+}
+
+// AssertPlanResources plans opts and fails the test unless the managed
+// resource addresses in the planned state are exactly expectedAddresses,
+// reporting the missing and unexpected ones.
+func AssertPlanResources(t *testing.T, opts *terraform.Options, expectedAddresses []string) {
+	t.Helper()
+
+	actual := plannedAddresses(showPlan(t, opts))
+	t.Logf("Plan for %s has %d resource(s)", opts.TerraformDir, len(actual))
+	if missing := missingFrom(expectedAddresses, actual); len(missing) > 0 {
+		t.Errorf("Plan for %s is missing resources: %s", opts.TerraformDir, strings.Join(missing, ", "))
+	}
+	if extra := missingFrom(actual, expectedAddresses); len(extra) > 0 {
+		t.Errorf("Plan for %s has unexpected resources: %s", opts.TerraformDir, strings.Join(extra, ", "))
+	}
+}
@@ -0,0 +1,143 @@
+package testhelpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// sampleRefreshOnlyPlan is trimmed `terraform show -json` output of a
+// `terraform plan -refresh-only` plan.
+const sampleRefreshOnlyPlan = `{
+  "format_version": "1.2",
+  "resource_drift": [
+    {
+      "address": "google_compute_firewall.allow_ssh",
+      "mode": "managed", "type": "google_compute_firewall", "name": "allow_ssh",
+      "change": {
+        "actions": ["update"],
+        "before": {"name": "allow-ssh", "source_ranges": ["35.235.240.0/20"], "priority": 1000},
+        "after": {"name": "allow-ssh", "source_ranges": ["0.0.0.0/0"], "priority": 900}
+      }
+    },
+    {
+      "address": "module.storage.google_storage_bucket.logs",
+      "mode": "managed", "type": "google_storage_bucket", "name": "logs",
+      "change": {"actions": ["delete"], "before": {"name": "logs"}, "after": null}
+    },
+    {
+      "address": "data.google_project.current",
+      "mode": "data", "type": "google_project", "name": "current",
+      "change": {"actions": ["update"], "before": {"number": "1"}, "after": {"number": "2"}}
+    }
+  ],
+  "resource_changes": [],
+  "planned_values": {"root_module": {}}
+}`
+
+func TestOutOfBandDrift(t *testing.T) {
+	plan, err := parsePlanJSON([]byte(sampleRefreshOnlyPlan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"google_compute_firewall.allow_ssh was changed outside Terraform (priority, source_ranges)",
+		"module.storage.google_storage_bucket.logs was deleted outside Terraform",
+	}
+	if got := outOfBandDrift(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("outOfBandDrift() = %v, want %v", got, want)
+	}
+
+	clean, err := parsePlanJSON([]byte(`{"format_version":"1.2","planned_values":{"root_module":{}}}`))
+	if err != nil || len(outOfBandDrift(clean)) != 0 {
+		t.Errorf("clean plan: %v, %v", outOfBandDrift(clean), err)
+	}
+}
+
+func TestPlanArgs(t *testing.T) {
+	opts := &terraform.Options{Lock: true, PlanFilePath: "/work/user.tfplan", Vars: map[string]interface{}{"region": "europe-west1"}}
+	args := planArgs(opts, "/tmp/test.tfplan", "-refresh-only")
+
+	if args[0] != "plan" || lastFlag(args, "out") != "/tmp/test.tfplan" || lastFlag(args, "lock") != "false" {
+		t.Errorf("plan args = %v, want plan to /tmp/test.tfplan with -lock=false", args)
+	}
+	if !strings.Contains(strings.Join(args, " "), "-refresh-only") {
+		t.Errorf("plan args = %v, want -refresh-only", args)
+	}
+	if !opts.Lock || opts.PlanFilePath != "/work/user.tfplan" {
+		t.Errorf("planArgs modified the caller's options: %+v", opts)
+	}
+}
+
+func TestDetectOutOfBandDriftStubbed(t *testing.T) {
+	var calls []string
+	var planFile string
+	stubTerraform(t, func(args ...string) (string, error) {
+		calls = append(calls, args[0])
+		if args[0] == "show" && args[len(args)-1] != planFile {
+			t.Errorf("show args = %v, want the saved plan %s", args, planFile)
+		}
+		if args[0] == "plan" {
+			planFile = lastFlag(args, "out")
+		}
+		if args[0] == "plan" && !strings.Contains(strings.Join(args, " "), "-refresh-only") {
+			t.Errorf("plan args = %v, want -refresh-only", args)
+		}
+		return `{"format_version":"1.2","resource_drift":[]}`, nil
+	})
+	if drift := DetectOutOfBandDrift(t, &terraform.Options{PlanFilePath: "user.tfplan"}); len(drift) != 0 {
+		t.Errorf("DetectOutOfBandDrift() = %v, want none", drift)
+	}
+	if !reflect.DeepEqual(calls, []string{"plan", "show"}) {
+		t.Errorf("terraform calls = %v, want plan then show", calls)
+	}
+}
+
+// samplePlan is trimmed `terraform show -json` output of a plan creating a
+// network in the root module and a bucket in a child module.
+const samplePlan = `{
+  "format_version": "1.2",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {"address": "google_compute_network.vpc", "mode": "managed", "type": "google_compute_network", "name": "vpc"},
+        {"address": "data.google_project.current", "mode": "data", "type": "google_project", "name": "current"}
+      ],
+      "child_modules": [
+        {
+          "address": "module.storage",
+          "resources": [
+            {"address": "module.storage.google_storage_bucket.logs[\"eu\"]", "mode": "managed", "type": "google_storage_bucket", "name": "logs"}
+          ]
+        }
+      ]
+    }
+  },
+  "resource_changes": []
+}`
+
+func TestPlannedAddresses(t *testing.T) {
+	plan, err := parsePlanJSON([]byte(samplePlan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"google_compute_network.vpc", `module.storage.google_storage_bucket.logs["eu"]`}
+	if got := plannedAddresses(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("plannedAddresses() = %v, want %v", got, want)
+	}
+	if got := plannedAddresses(tfPlan{}); len(got) != 0 {
+		t.Errorf("plannedAddresses() of empty plan = %v", got)
+	}
+}
+
+func TestAssertPlanResourcesStubbed(t *testing.T) {
+	stubTerraform(t, func(args ...string) (string, error) {
+		return samplePlan, nil
+	})
+	AssertPlanResources(t, &terraform.Options{}, []string{
+		`module.storage.google_storage_bucket.logs["eu"]`,
+		"google_compute_network.vpc",
+	})
+}
@@ -0,0 +1,169 @@
+package testhelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// enabledService mirrors an entry of `gcloud services list --enabled`.
+type enabledService struct {
+	Config struct {
+		Name string `json:"name"`
+	} `json:"config"`
+}
+
+// missingFrom returns the entries of required absent from actual, sorted.
+func missingFrom(required, actual []string) []string {
+	have := make(map[string]bool, len(actual))
+	for _, a := range actual {
+		have[a] = true
+	}
+	var missing []string
+	for _, r := range required {
+		if !have[r] {
+			missing = append(missing, r)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// AssertAPIsEnabled fails the test listing any required API (for example
+// compute.googleapis.com) that is not enabled on the project.
+func AssertAPIsEnabled(t *testing.T, projectID string, required []string) {
+	t.Helper()
+
+	var services []enabledService
+	if err := gcloudJSON(&services, "services", "list", "--enabled", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list enabled services for project %s: %v", projectID, err)
+	}
+
+	enabled := make([]string, 0, len(services))
+	for _, s := range services {
+		enabled = append(enabled, s.Config.Name)
+	}
+	t.Logf("Project %s has %d enabled APIs", projectID, len(enabled))
+
+	if missing := missingFrom(required, enabled); len(missing) > 0 {
+		t.Errorf("Project %s is missing required APIs: %s", projectID, strings.Join(missing, ", "))
+	}
+}
+
+// computeQuota mirrors a quota entry of `gcloud compute project-info describe`.
+type computeQuota struct {
+	Metric string  `json:"metric"`
+	Limit  float64 `json:"limit"`
+	Usage  float64 `json:"usage"`
+}
+
+// quotaHeadroom is the outcome of adding planned resources to one quota.
+type quotaHeadroom struct {
+	Metric  string
+	Limit   float64
+	Usage   float64
+	Planned int
+}
+
+// remaining is the quota left after the planned additions.
+func (h quotaHeadroom) remaining() float64 {
+	return h.Limit - h.Usage - float64(h.Planned)
+}
+
+// utilization is the fraction of the limit in use after the planned additions.
+func (h quotaHeadroom) utilization() float64 {
+	if h.Limit <= 0 {
+		return 1
+	}
+	return (h.Usage + float64(h.Planned)) / h.Limit
+}
+
+// evaluateQuotas computes headroom for each planned metric, tightest first,
+// and returns a problem for every metric that would be exceeded or that is
+// not reported.
+func evaluateQuotas(quotas []computeQuota, planned map[string]int) ([]quotaHeadroom, []string) {
+	byMetric := make(map[string]computeQuota, len(quotas))
+	for _, q := range quotas {
+		byMetric[q.Metric] = q
+	}
+
+	var headroom []quotaHeadroom
+	var problems []string
+	for metric, count := range planned {
+		q, ok := byMetric[metric]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: quota not reported", metric))
+			continue
+		}
+		h := quotaHeadroom{Metric: metric, Limit: q.Limit, Usage: q.Usage, Planned: count}
+		if h.remaining() < 0 {
+			problems = append(problems, fmt.Sprintf("%s: usage %.0f + planned %d exceeds limit %.0f",
+				metric, h.Usage, h.Planned, h.Limit))
+		}
+		headroom = append(headroom, h)
+	}
+
+	sort.Slice(headroom, func(i, j int) bool {
+		if headroom[i].utilization() != headroom[j].utilization() {
+			return headroom[i].utilization() > headroom[j].utilization()
+		}
+		return headroom[i].Metric < headroom[j].Metric
+	})
+	sort.Strings(problems)
+	return headroom, problems
+}
+
+// mergeQuotas returns the project-wide quotas with the regional ones added.
+// A metric reported by both is taken from the region, since that is the
+// limit a regional resource counts against.
+func mergeQuotas(project, regional []computeQuota) []computeQuota {
+	merged := make([]computeQuota, 0, len(project)+len(regional))
+	inRegion := make(map[string]bool, len(regional))
+	for _, q := range regional {
+		inRegion[q.Metric] = true
+	}
+	for _, q := range project {
+		if !inRegion[q.Metric] {
+			merged = append(merged, q)
+		}
+	}
+	return append(merged, regional...)
+}
+
+// PreflightQuota fails the test if adding plannedCounts (keyed by Compute
+// quota metric) to current usage would exceed any quota. Project-wide
+// metrics such as CPUS_ALL_REGIONS and NETWORKS come from the project;
+// regional metrics such as CPUS, IN_USE_ADDRESSES and STATIC_ADDRESSES come
+// from region, which may be empty when only project-wide metrics are
+// planned. The tightest quota is logged.
+func PreflightQuota(t *testing.T, projectID, region string, plannedCounts map[string]int) {
+	t.Helper()
+
+	var info struct {
+		Quotas []computeQuota `json:"quotas"`
+	}
+	if err := gcloudJSON(&info, "compute", "project-info", "describe", "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe project %s quotas: %v", projectID, err)
+	}
+	quotas := info.Quotas
+	if region != "" {
+		var regionInfo struct {
+			Quotas []computeQuota `json:"quotas"`
+		}
+		if err := gcloudJSON(&regionInfo, "compute", "regions", "describe", region, "--project", projectID); err != nil {
+			t.Fatalf("Failed to describe region %s quotas in project %s: %v", region, projectID, err)
+		}
+		quotas = mergeQuotas(quotas, regionInfo.Quotas)
+	}
+
+	headroom, problems := evaluateQuotas(quotas, plannedCounts)
+	if len(headroom) > 0 {
+		tightest := headroom[0]
+		t.Logf("Tightest quota %s: usage %.0f + planned %d of limit %.0f (%.0f remaining)",
+			tightest.Metric, tightest.Usage, tightest.Planned, tightest.Limit, tightest.remaining())
+	}
+	for _, p := range problems {
+		t.Errorf("Project %s quota preflight: %s", projectID, p)
+	}
+}
@@ -0,0 +1,77 @@
+package testhelpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingFrom(t *testing.T) {
+	enabled := []string{"compute.googleapis.com", "dns.googleapis.com"}
+
+	if got := missingFrom([]string{"compute.googleapis.com"}, enabled); len(got) != 0 {
+		t.Errorf("missingFrom() = %v, want none", got)
+	}
+
+	got := missingFrom([]string{"sqladmin.googleapis.com", "compute.googleapis.com", "container.googleapis.com"}, enabled)
+	want := []string{"container.googleapis.com", "sqladmin.googleapis.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missingFrom() = %v, want %v", got, want)
+	}
+}
+
+func TestAssertAPIsEnabledDecodesServices(t *testing.T) {
+	stubGcloud(t, `[{"config":{"name":"compute.googleapis.com"},"state":"ENABLED"}]`)
+	AssertAPIsEnabled(t, "p", []string{"compute.googleapis.com"})
+}
+
+func TestEvaluateQuotas(t *testing.T) {
+	quotas := []computeQuota{
+		{Metric: "CPUS_ALL_REGIONS", Limit: 32, Usage: 20},
+		{Metric: "NETWORKS", Limit: 15, Usage: 2},
+		{Metric: "STATIC_ADDRESSES", Limit: 8, Usage: 7},
+	}
+
+	headroom, problems := evaluateQuotas(quotas, map[string]int{"CPUS_ALL_REGIONS": 8, "NETWORKS": 1})
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if headroom[0].Metric != "CPUS_ALL_REGIONS" || headroom[0].remaining() != 4 {
+		t.Errorf("tightest = %+v, want CPUS_ALL_REGIONS with 4 remaining", headroom[0])
+	}
+
+	_, problems = evaluateQuotas(quotas, map[string]int{"STATIC_ADDRESSES": 2, "GPUS": 1})
+	want := []string{
+		"GPUS: quota not reported",
+		"STATIC_ADDRESSES: usage 7 + planned 2 exceeds limit 8",
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems = %v, want %v", problems, want)
+	}
+}
+
+func TestEvaluateQuotasExactFit(t *testing.T) {
+	_, problems := evaluateQuotas([]computeQuota{{Metric: "NETWORKS", Limit: 5, Usage: 3}}, map[string]int{"NETWORKS": 2})
+	if len(problems) != 0 {
+		t.Errorf("filling a quota exactly should pass, got %v", problems)
+	}
+}
+
+func TestMergeQuotas(t *testing.T) {
+	project := []computeQuota{
+		{Metric: "CPUS_ALL_REGIONS", Limit: 32, Usage: 20},
+		{Metric: "STATIC_ADDRESSES", Limit: 100, Usage: 0},
+	}
+	regional := []computeQuota{
+		{Metric: "CPUS", Limit: 24, Usage: 22},
+		{Metric: "STATIC_ADDRESSES", Limit: 8, Usage: 7},
+	}
+
+	_, problems := evaluateQuotas(mergeQuotas(project, regional), map[string]int{"CPUS_ALL_REGIONS": 4, "CPUS": 4, "STATIC_ADDRESSES": 2})
+	want := []string{
+		"CPUS: usage 22 + planned 4 exceeds limit 24",
+		"STATIC_ADDRESSES: usage 7 + planned 2 exceeds limit 8",
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems = %v, want %v", problems, want)
+	}
+}
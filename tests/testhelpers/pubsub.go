@@ -0,0 +1,128 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pubsubSubscription mirrors the fields of a Pub/Sub subscription used by
+// the assertions in this file.
+type pubsubSubscription struct {
+	Name             string `json:"name"`
+	DeadLetterPolicy *struct {
+		DeadLetterTopic     string `json:"deadLetterTopic"`
+		MaxDeliveryAttempts int    `json:"maxDeliveryAttempts"`
+	} `json:"deadLetterPolicy"`
+}
+
+func describeSubscription(t *testing.T, projectID, subscription string) pubsubSubscription {
+	t.Helper()
+
+	var sub pubsubSubscription
+	if err := gcloudJSON(&sub, "pubsub", "subscriptions", "describe", subscription, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe subscription %s: %v", subscription, err)
+	}
+	return sub
+}
+
+// checkDeadLetterPolicy returns the differences between the subscription's
+// dead-letter policy and the expected topic and delivery attempts. Topics
+// compare by short name.
+func checkDeadLetterPolicy(sub pubsubSubscription, expectTopic string, maxDeliveryAttempts int) []string {
+	dlp := sub.DeadLetterPolicy
+	if dlp == nil || dlp.DeadLetterTopic == "" {
+		return []string{"no dead-letter topic configured"}
+	}
+
+	var problems []string
+	if lastSegment(dlp.DeadLetterTopic) != lastSegment(expectTopic) {
+		problems = append(problems, fmt.Sprintf("dead-letter topic is %s, want %s", dlp.DeadLetterTopic, expectTopic))
+	}
+	if dlp.MaxDeliveryAttempts != maxDeliveryAttempts {
+		problems = append(problems, fmt.Sprintf("max delivery attempts is %d, want %d", dlp.MaxDeliveryAttempts, maxDeliveryAttempts))
+	}
+	return problems
+}
+
+// AssertDeadLetterPolicy fails the test unless the subscription dead-letters
+// to expectTopic after maxDeliveryAttempts attempts.
+func AssertDeadLetterPolicy(t *testing.T, projectID, subscription string, expectTopic string, maxDeliveryAttempts int) {
+	t.Helper()
+
+	sub := describeSubscription(t, projectID, subscription)
+	if dlp := sub.DeadLetterPolicy; dlp != nil {
+		t.Logf("Subscription %s dead-letter topic=%s maxDeliveryAttempts=%d", subscription, dlp.DeadLetterTopic, dlp.MaxDeliveryAttempts)
+	}
+	for _, p := range checkDeadLetterPolicy(sub, expectTopic, maxDeliveryAttempts) {
+		t.Errorf("Subscription %s: %s", subscription, p)
+	}
+}
+
+// defaultSubscriptionRetention applies when a subscription does not set
+// messageRetentionDuration. Topics retain nothing unless configured.
+const defaultSubscriptionRetention = 7 * 24 * time.Hour
+
+// parseRetention parses a messageRetentionDuration such as "604800s". An
+// empty value means the resource default.
+func parseRetention(value string, isSubscription bool) (time.Duration, error) {
+	if value == "" {
+		if isSubscription {
+			return defaultSubscriptionRetention, nil
+		}
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid message retention duration %q", value)
+	}
+	return d, nil
+}
+
+// checkMessageRetention returns an error if actual is below minRetention.
+func checkMessageRetention(actual, minRetention time.Duration) error {
+	if actual < minRetention {
+		return fmt.Errorf("message retention is %s, want at least %s", actual, minRetention)
+	}
+	return nil
+}
+
+// AssertMessageRetention fails the test if the topic or subscription
+// retains messages for less than minRetention. topicOrSub may be a full
+// projects/P/topics/T or projects/P/subscriptions/S name; a short name is
+// looked up as a subscription first, then as a topic.
+func AssertMessageRetention(t *testing.T, projectID, topicOrSub string, minRetention time.Duration) {
+	t.Helper()
+
+	var resource struct {
+		MessageRetentionDuration string `json:"messageRetentionDuration"`
+	}
+	kind := "subscription"
+	var err error
+	switch {
+	case strings.Contains(topicOrSub, "/topics/"):
+		kind = "topic"
+		err = gcloudJSON(&resource, "pubsub", "topics", "describe", topicOrSub, "--project", projectID)
+	case strings.Contains(topicOrSub, "/subscriptions/"):
+		err = gcloudJSON(&resource, "pubsub", "subscriptions", "describe", topicOrSub, "--project", projectID)
+	default:
+		err = gcloudJSON(&resource, "pubsub", "subscriptions", "describe", topicOrSub, "--project", projectID)
+		if isNotFound(err) {
+			kind = "topic"
+			err = gcloudJSON(&resource, "pubsub", "topics", "describe", topicOrSub, "--project", projectID)
+		}
+	}
+	if err != nil {
+		t.Fatalf("Failed to describe Pub/Sub %s %s: %v", kind, topicOrSub, err)
+	}
+
+	actual, err := parseRetention(resource.MessageRetentionDuration, kind == "subscription")
+	if err != nil {
+		t.Fatalf("Pub/Sub %s %s: %v", kind, topicOrSub, err)
+	}
+	t.Logf("Pub/Sub %s %s message retention: %s", kind, topicOrSub, actual)
+	if err := checkMessageRetention(actual, minRetention); err != nil {
+		t.Errorf("Pub/Sub %s %s: %v", kind, topicOrSub, err)
+	}
+}
@@ -0,0 +1,81 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckDeadLetterPolicy(t *testing.T) {
+	cases := []struct {
+		name         string
+		raw          string
+		wantProblems int
+	}{
+		{"matches", `{"deadLetterPolicy":{"deadLetterTopic":"projects/p/topics/orders-dlq","maxDeliveryAttempts":5}}`, 0},
+		{"wrong attempts", `{"deadLetterPolicy":{"deadLetterTopic":"projects/p/topics/orders-dlq","maxDeliveryAttempts":10}}`, 1},
+		{"wrong topic and attempts", `{"deadLetterPolicy":{"deadLetterTopic":"projects/p/topics/other","maxDeliveryAttempts":10}}`, 2},
+		{"no policy", `{}`, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sub pubsubSubscription
+			if err := json.Unmarshal([]byte(tc.raw), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if got := checkDeadLetterPolicy(sub, "orders-dlq", 5); len(got) != tc.wantProblems {
+				t.Errorf("checkDeadLetterPolicy() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	cases := []struct {
+		value          string
+		isSubscription bool
+		want           time.Duration
+	}{
+		{"604800s", true, 7 * 24 * time.Hour},
+		{"86400s", false, 24 * time.Hour},
+		{"", true, defaultSubscriptionRetention},
+		{"", false, 0},
+	}
+	for _, tc := range cases {
+		if got, err := parseRetention(tc.value, tc.isSubscription); err != nil || got != tc.want {
+			t.Errorf("parseRetention(%q, %t) = %s, %v, want %s", tc.value, tc.isSubscription, got, err, tc.want)
+		}
+	}
+	if _, err := parseRetention("7 days", true); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestCheckMessageRetention(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	if err := checkMessageRetention(week, week); err != nil {
+		t.Errorf("equal retention: %v", err)
+	}
+	if err := checkMessageRetention(24*time.Hour, week); err == nil {
+		t.Error("expected an error for retention below the minimum")
+	}
+}
+
+func TestAssertMessageRetentionFallsBackToTopic(t *testing.T) {
+	orig := commandRunner
+	t.Cleanup(func() { commandRunner = orig })
+	var described []string
+	commandRunner = func(name string, args ...string) ([]byte, error) {
+		described = append(described, args[1])
+		if args[1] == "subscriptions" {
+			return nil, errors.New("NOT_FOUND: Resource not found")
+		}
+		return []byte(`{"name":"projects/p/topics/events","messageRetentionDuration":"1209600s"}`), nil
+	}
+
+	AssertMessageRetention(t, "p", "events", 7*24*time.Hour)
+	if len(described) != 2 || described[1] != "topics" {
+		t.Errorf("described %v, want subscriptions then topics", described)
+	}
+}
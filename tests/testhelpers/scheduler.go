@@ -0,0 +1,55 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// schedulerJob mirrors the fields of a Cloud Scheduler job used by the
+// assertions in this file.
+type schedulerJob struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Schedule string `json:"schedule"`
+	TimeZone string `json:"timeZone"`
+}
+
+// isNotFound reports whether a gcloud error describes a missing resource.
+func isNotFound(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "NOT_FOUND") || strings.Contains(err.Error(), "was not found"))
+}
+
+// checkSchedulerJob returns the reasons the job is not an enabled job on
+// expectSchedule. Cron fields are compared ignoring extra whitespace.
+func checkSchedulerJob(job schedulerJob, expectSchedule string) []string {
+	var problems []string
+	if job.State != "ENABLED" {
+		problems = append(problems, fmt.Sprintf("state is %s, want ENABLED", job.State))
+	}
+	if strings.Join(strings.Fields(job.Schedule), " ") != strings.Join(strings.Fields(expectSchedule), " ") {
+		problems = append(problems, fmt.Sprintf("schedule is %q, want %q", job.Schedule, expectSchedule))
+	}
+	return problems
+}
+
+// AssertSchedulerJob fails the test if the Cloud Scheduler job is missing,
+// paused, or scheduled differently from expectSchedule.
+func AssertSchedulerJob(t *testing.T, projectID, region, jobName string, expectSchedule string) {
+	t.Helper()
+
+	var job schedulerJob
+	err := gcloudJSON(&job, "scheduler", "jobs", "describe", jobName, "--location", region, "--project", projectID)
+	if isNotFound(err) {
+		t.Errorf("Cloud Scheduler job %s does not exist in %s", jobName, region)
+		return
+	}
+	if err != nil {
+		t.Fatalf("Failed to describe Cloud Scheduler job %s: %v", jobName, err)
+	}
+
+	t.Logf("Cloud Scheduler job %s state=%s schedule=%q timeZone=%s", jobName, job.State, job.Schedule, job.TimeZone)
+	for _, p := range checkSchedulerJob(job, expectSchedule) {
+		t.Errorf("Cloud Scheduler job %s: %s", jobName, p)
+	}
+}
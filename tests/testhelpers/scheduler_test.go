@@ -0,0 +1,35 @@
+package testhelpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckSchedulerJob(t *testing.T) {
+	cases := []struct {
+		name         string
+		job          schedulerJob
+		wantProblems int
+	}{
+		{"enabled on schedule", schedulerJob{State: "ENABLED", Schedule: "0 3 * * *"}, 0},
+		{"extra whitespace", schedulerJob{State: "ENABLED", Schedule: "0  3 * *  *"}, 0},
+		{"paused", schedulerJob{State: "PAUSED", Schedule: "0 3 * * *"}, 1},
+		{"different schedule", schedulerJob{State: "ENABLED", Schedule: "0 4 * * *"}, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkSchedulerJob(tc.job, "0 3 * * *"); len(got) != tc.wantProblems {
+				t.Errorf("checkSchedulerJob() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	if !isNotFound(errors.New("ERROR: (gcloud.scheduler.jobs.describe) NOT_FOUND: Job not found.")) {
+		t.Error("NOT_FOUND error not detected")
+	}
+	if isNotFound(errors.New("PERMISSION_DENIED")) || isNotFound(nil) {
+		t.Error("unexpected not-found match")
+	}
+}
@@ -0,0 +1,208 @@
+package testhelpers
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// secretNames are attribute name suffixes that suggest secret material.
+const secretNames = `password|passwd|private_key|api_key|access_key|client_secret|secret_data|token`
+
+var (
+	// SecretAttributePattern matches an assignment to an attribute whose name
+	// suggests secret material. AssertNoInlineSecrets flags it when the value
+	// is a non-empty string literal rather than a reference.
+	SecretAttributePattern = regexp.MustCompile(`(?i)^"?([a-z0-9_]*(` + secretNames + `))"?\s*=\s*(.+)$`)
+
+	// InlineSecretPatterns match secret material anywhere on a line: PEM
+	// private key headers, in plain text or base64-encoded.
+	InlineSecretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`LS0tLS1CRUdJTi[A-Za-z0-9+/=]{20,}`),
+	}
+
+	// SecretValuePatterns match values that look like credentials wherever
+	// they appear: private keys, service account key files, Google API keys
+	// and OAuth access tokens.
+	SecretValuePatterns = append([]*regexp.Regexp{
+		regexp.MustCompile(`"private_key_id"\s*:`),
+		regexp.MustCompile(`AIza[0-9A-Za-z_-]{35}`),
+		regexp.MustCompile(`ya29\.[0-9A-Za-z_-]{20,}`),
+	}, InlineSecretPatterns...)
+)
+
+var secretNameRe = regexp.MustCompile(`(?i)(` + secretNames + `)$`)
+
+// pureInterpolationRe matches a string literal that only interpolates an
+// expression, e.g. "${var.db_password}".
+var pureInterpolationRe = regexp.MustCompile(`^"\$\{[^}]*\}"$`)
+
+// isInlineLiteral reports whether an attribute value is a non-empty string
+// literal, as opposed to a reference, function call or interpolation.
+func isInlineLiteral(value string) bool {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	if len(value) < 3 || value[0] != '"' || value[len(value)-1] != '"' {
+		return false
+	}
+	return !pureInterpolationRe.MatchString(value)
+}
+
+// scanInlineSecrets returns "file:line: reason" for every line of a .tf
+// file that inlines a secret. Comment lines are skipped.
+func scanInlineSecrets(name string, lines []string) []string {
+	var findings []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if m := SecretAttributePattern.FindStringSubmatch(trimmed); m != nil && isInlineLiteral(m[3]) {
+			findings = append(findings, fmt.Sprintf("%s:%d: %s is assigned a string literal", name, i+1, m[1]))
+			continue
+		}
+		for _, re := range InlineSecretPatterns {
+			if re.MatchString(line) {
+				findings = append(findings, fmt.Sprintf("%s:%d: matches %s", name, i+1, re))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// AssertNoInlineSecrets fails the test listing every line of the .tf files
+// in modulePath that assigns a literal to a secret-like attribute
+// (SecretAttributePattern) or contains private key material
+// (InlineSecretPatterns). Variable, local and data references are accepted.
+func AssertNoInlineSecrets(t *testing.T, modulePath string) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil {
+		t.Fatalf("Failed to list .tf files in %s: %v", modulePath, err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		lines, err := readLines(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		for _, f := range scanInlineSecrets(filepath.Base(file), lines) {
+			t.Errorf("Inline secret in %s: %s", modulePath, f)
+		}
+	}
+}
+
+// looksSecret reports whether a string value stored under key looks like a
+// credential, either by the key's name or by SecretValuePatterns.
+func looksSecret(key, value string) bool {
+	if value == "" {
+		return false
+	}
+	if secretNameRe.MatchString(key) {
+		return true
+	}
+	for _, re := range SecretValuePatterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// exposedSecretPaths returns the paths under prefix of secret-looking string
+// values in value that the sensitivity mask does not cover. mask mirrors
+// value with true at sensitive positions, as in a plan's after_sensitive.
+func exposedSecretPaths(prefix, key string, value, mask interface{}) []string {
+	if m, ok := mask.(bool); ok && m {
+		return nil
+	}
+
+	var paths []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		sub, _ := mask.(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			paths = append(paths, exposedSecretPaths(prefix+"."+k, k, v[k], sub[k])...)
+		}
+	case []interface{}:
+		sub, _ := mask.([]interface{})
+		for i, item := range v {
+			var itemMask interface{}
+			if i < len(sub) {
+				itemMask = sub[i]
+			}
+			paths = append(paths, exposedSecretPaths(fmt.Sprintf("%s[%d]", prefix, i), key, item, itemMask)...)
+		}
+	case string:
+		if looksSecret(key, v) {
+			paths = append(paths, prefix)
+		}
+	}
+	return paths
+}
+
+// planSensitiveExposures returns the resource attribute and output paths in
+// the plan whose planned values look secret but are not marked sensitive.
+func planSensitiveExposures(plan tfPlan) []string {
+	var exposures []string
+	for _, c := range plan.ResourceChanges {
+		exposures = append(exposures, exposedSecretPaths(c.Address, "", c.Change.After, c.Change.AfterSensitive)...)
+	}
+
+	names := make([]string, 0, len(plan.OutputChanges))
+	for name := range plan.OutputChanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := plan.OutputChanges[name]
+		exposures = append(exposures, exposedSecretPaths("output."+name, name, o.After, o.AfterSensitive)...)
+	}
+	return exposures
+}
+
+// AssertPlanNoSensitiveExposure plans opts and fails the test for every
+// planned resource attribute or output that is not marked sensitive but
+// whose value looks like a secret (by attribute name or
+// SecretValuePatterns). Only the address is reported; values are redacted.
+func AssertPlanNoSensitiveExposure(t *testing.T, opts *terraform.Options) {
+	t.Helper()
+
+	for _, path := range planSensitiveExposures(showPlan(t, opts)) {
+		t.Errorf("Plan for %s exposes a secret-like value at %s (value redacted); mark it sensitive", opts.TerraformDir, path)
+	}
+}
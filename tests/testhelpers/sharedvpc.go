@@ -0,0 +1,139 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const networkUserRole = "roles/compute.networkUser"
+
+// sharedVPCAccess is the state needed to decide whether a service project
+// can use a host project subnet.
+type sharedVPCAccess struct {
+	AssociatedProjects []string
+	ServiceProject     string
+	ServiceProjectNum  string
+	SubnetPolicy       IAMPolicy
+	HostPolicy         IAMPolicy
+}
+
+// belongsToProject reports whether an IAM member is a service account owned
+// by the project: a user-managed account (…@PROJECT.iam.gserviceaccount.com)
+// or one of the Google-managed forms keyed by project number:
+// NUMBER@cloudservices.gserviceaccount.com,
+// NUMBER-compute@developer.gserviceaccount.com and service agents
+// service-NUMBER@….gserviceaccount.com.
+func belongsToProject(member, projectID, projectNumber string) bool {
+	email, ok := strings.CutPrefix(member, "serviceAccount:")
+	if !ok {
+		return false
+	}
+	if strings.HasSuffix(email, "@"+projectID+".iam.gserviceaccount.com") {
+		return true
+	}
+	if projectNumber == "" {
+		return false
+	}
+	local, domain, _ := strings.Cut(email, "@")
+	switch {
+	case local == projectNumber:
+		return domain == "cloudservices.gserviceaccount.com"
+	case local == projectNumber+"-compute":
+		return domain == "developer.gserviceaccount.com"
+	case local == "service-"+projectNumber:
+		return strings.HasSuffix(domain, ".gserviceaccount.com")
+	}
+	return false
+}
+
+// grantsNetworkUser reports whether policy grants compute.networkUser to a
+// service account of the service project.
+func grantsNetworkUser(policy IAMPolicy, projectID, projectNumber string) bool {
+	for _, b := range policy.Bindings {
+		if b.Role != networkUserRole {
+			continue
+		}
+		for _, m := range b.Members {
+			if belongsToProject(m, projectID, projectNumber) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluateSharedVPCAccess returns what is missing for the service project to
+// use the subnet: attachment to the host and a networkUser grant on the
+// subnet or host project.
+func evaluateSharedVPCAccess(a sharedVPCAccess) []string {
+	var missing []string
+	attached := false
+	for _, p := range a.AssociatedProjects {
+		if p == a.ServiceProject {
+			attached = true
+		}
+	}
+	if !attached {
+		missing = append(missing, "service project is not attached to the host project")
+	}
+	if !grantsNetworkUser(a.SubnetPolicy, a.ServiceProject, a.ServiceProjectNum) &&
+		!grantsNetworkUser(a.HostPolicy, a.ServiceProject, a.ServiceProjectNum) {
+		missing = append(missing, fmt.Sprintf("no %s grant to a service project service account on the subnet or host project", networkUserRole))
+	}
+	return missing
+}
+
+// parseSubnetRef splits "REGION/SUBNET" or
+// "projects/P/regions/REGION/subnetworks/SUBNET" into region and name.
+func parseSubnetRef(subnet string) (region, name string, err error) {
+	parts := strings.Split(strings.Trim(subnet, "/"), "/")
+	switch {
+	case len(parts) == 2:
+		return parts[0], parts[1], nil
+	case len(parts) >= 4 && parts[len(parts)-4] == "regions" && parts[len(parts)-2] == "subnetworks":
+		return parts[len(parts)-3], parts[len(parts)-1], nil
+	}
+	return "", "", fmt.Errorf("subnet %q must be REGION/SUBNET or a subnetwork resource name", subnet)
+}
+
+// AssertCrossProjectReference fails the test unless serviceProject is a
+// Shared VPC service project of hostProject with compute.networkUser on the
+// subnet (given as REGION/SUBNET or a subnetwork resource name).
+func AssertCrossProjectReference(t *testing.T, hostProject, serviceProject, subnet string) {
+	t.Helper()
+
+	region, name, err := parseSubnetRef(subnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var associated []struct {
+		ID string `json:"id"`
+	}
+	if err := gcloudJSON(&associated, "compute", "shared-vpc", "associated-projects", "list", hostProject); err != nil {
+		t.Fatalf("Failed to list Shared VPC service projects of %s: %v", hostProject, err)
+	}
+	var project struct {
+		ProjectNumber string `json:"projectNumber"`
+	}
+	if err := gcloudJSON(&project, "projects", "describe", serviceProject); err != nil {
+		t.Fatalf("Failed to describe project %s: %v", serviceProject, err)
+	}
+
+	access := sharedVPCAccess{ServiceProject: serviceProject, ServiceProjectNum: project.ProjectNumber}
+	for _, a := range associated {
+		access.AssociatedProjects = append(access.AssociatedProjects, a.ID)
+	}
+	if err := gcloudJSON(&access.SubnetPolicy, "compute", "networks", "subnets", "get-iam-policy", name,
+		"--region", region, "--project", hostProject); err != nil {
+		t.Fatalf("Failed to get IAM policy of subnet %s: %v", subnet, err)
+	}
+	if err := gcloudJSON(&access.HostPolicy, "projects", "get-iam-policy", hostProject); err != nil {
+		t.Fatalf("Failed to get IAM policy of project %s: %v", hostProject, err)
+	}
+
+	for _, m := range evaluateSharedVPCAccess(access) {
+		t.Errorf("%s cannot use %s in %s: %s", serviceProject, subnet, hostProject, m)
+	}
+}
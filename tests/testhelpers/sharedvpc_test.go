@@ -0,0 +1,63 @@
+package testhelpers
+
+import "testing"
+
+func TestEvaluateSharedVPCAccess(t *testing.T) {
+	subnetGrant := IAMPolicy{Bindings: []IAMBinding{{Role: networkUserRole,
+		Members: []string{"serviceAccount:123456789@cloudservices.gserviceaccount.com"}}}}
+	hostGrant := IAMPolicy{Bindings: []IAMBinding{{Role: networkUserRole,
+		Members: []string{"serviceAccount:deployer@svc-proj.iam.gserviceaccount.com"}}}}
+	otherGrant := IAMPolicy{Bindings: []IAMBinding{{Role: networkUserRole,
+		Members: []string{"serviceAccount:deployer@other-proj.iam.gserviceaccount.com", "user:dev@example.com"}}}}
+
+	cases := []struct {
+		name        string
+		access      sharedVPCAccess
+		wantMissing int
+	}{
+		{"subnet grant", sharedVPCAccess{AssociatedProjects: []string{"svc-proj"}, SubnetPolicy: subnetGrant}, 0},
+		{"host grant", sharedVPCAccess{AssociatedProjects: []string{"svc-proj"}, HostPolicy: hostGrant}, 0},
+		{"not attached", sharedVPCAccess{AssociatedProjects: []string{"other-proj"}, SubnetPolicy: subnetGrant}, 1},
+		{"grant to other project", sharedVPCAccess{AssociatedProjects: []string{"svc-proj"}, SubnetPolicy: otherGrant}, 1},
+		{"nothing", sharedVPCAccess{}, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.access.ServiceProject, tc.access.ServiceProjectNum = "svc-proj", "123456789"
+			if got := evaluateSharedVPCAccess(tc.access); len(got) != tc.wantMissing {
+				t.Errorf("evaluateSharedVPCAccess() = %v, want %d missing", got, tc.wantMissing)
+			}
+		})
+	}
+}
+
+func TestBelongsToProject(t *testing.T) {
+	cases := map[string]bool{
+		"serviceAccount:deployer@svc-proj.iam.gserviceaccount.com":                        true,
+		"serviceAccount:123456789@cloudservices.gserviceaccount.com":                      true,
+		"serviceAccount:123456789-compute@developer.gserviceaccount.com":                  true,
+		"serviceAccount:service-123456789@container-engine-robot.iam.gserviceaccount.com": true,
+		"serviceAccount:9123456789@cloudservices.gserviceaccount.com":                     false,
+		"serviceAccount:service-1234567890@gcp-sa-run.iam.gserviceaccount.com":            false,
+		"serviceAccount:123456789@example.com":                                            false,
+		"serviceAccount:deploy-123456789@other-proj.iam.gserviceaccount.com":              false,
+		"user:123456789@example.com":                                                      false,
+	}
+	for member, want := range cases {
+		if got := belongsToProject(member, "svc-proj", "123456789"); got != want {
+			t.Errorf("belongsToProject(%q) = %t, want %t", member, got, want)
+		}
+	}
+}
+
+func TestParseSubnetRef(t *testing.T) {
+	for _, in := range []string{"europe-west1/shared", "projects/host/regions/europe-west1/subnetworks/shared"} {
+		region, name, err := parseSubnetRef(in)
+		if err != nil || region != "europe-west1" || name != "shared" {
+			t.Errorf("parseSubnetRef(%q) = %q, %q, %v", in, region, name, err)
+		}
+	}
+	if _, _, err := parseSubnetRef("shared"); err == nil {
+		t.Error("expected an error for a bare subnet name")
+	}
+}
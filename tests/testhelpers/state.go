@@ -0,0 +1,101 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// tfModule is a module in `terraform show -json` output. The same shape is
+// used for state values and plan planned_values.
+type tfModule struct {
+	Resources    []tfResource `json:"resources"`
+	ChildModules []tfModule   `json:"child_modules"`
+}
+
+// tfResource is a resource instance in a tfModule.
+type tfResource struct {
+	Address string                 `json:"address"`
+	Mode    string                 `json:"mode"`
+	Type    string                 `json:"type"`
+	Name    string                 `json:"name"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// tfState is the subset of `terraform show -json` state output the helpers
+// use.
+type tfState struct {
+	Values *struct {
+		RootModule tfModule `json:"root_module"`
+	} `json:"values"`
+}
+
+func parseStateJSON(raw []byte) (tfState, error) {
+	var state tfState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return tfState{}, fmt.Errorf("decoding terraform state JSON: %w", err)
+	}
+	return state, nil
+}
+
+// walk calls fn for every resource in the module and its descendants.
+func (m tfModule) walk(fn func(tfResource)) {
+	for _, r := range m.Resources {
+		fn(r)
+	}
+	for _, child := range m.ChildModules {
+		child.walk(fn)
+	}
+}
+
+// resources returns every resource instance in the state, including those
+// in child modules. An empty state has none.
+func (s tfState) resources() []tfResource {
+	if s.Values == nil {
+		return nil
+	}
+	var all []tfResource
+	s.Values.RootModule.walk(func(r tfResource) { all = append(all, r) })
+	return all
+}
+
+// countManagedResources counts managed (non-data) resource instances of
+// resourceType.
+func countManagedResources(resources []tfResource, resourceType string) int {
+	n := 0
+	for _, r := range resources {
+		if r.Mode == "managed" && r.Type == resourceType {
+			n++
+		}
+	}
+	return n
+}
+
+// showState returns the parsed state for opts.
+func showState(t *testing.T, opts *terraform.Options) tfState {
+	t.Helper()
+
+	out, err := terraformStdoutRunner(t, opts, "show", "-json", "-no-color")
+	if err != nil {
+		t.Fatalf("terraform show -json failed: %v", err)
+	}
+	state, err := parseStateJSON([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+// AssertResourceCount fails the test unless the state holds exactly expected
+// managed instances of resourceType (e.g. google_compute_subnetwork).
+func AssertResourceCount(t *testing.T, opts *terraform.Options, resourceType string, expected int) {
+	t.Helper()
+
+	actual := countManagedResources(showState(t, opts).resources(), resourceType)
+	t.Logf("State has %d %s resource(s), expected %d", actual, resourceType, expected)
+	if actual != expected {
+		t.Errorf("Found %d %s resource(s) in state, want %d", actual, resourceType, expected)
+	}
+}
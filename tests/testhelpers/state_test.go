@@ -0,0 +1,69 @@
+package testhelpers
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+const sampleState = `{
+  "format_version": "1.0",
+  "values": {
+    "root_module": {
+      "resources": [
+        {"address": "google_compute_network.vpc", "mode": "managed", "type": "google_compute_network", "name": "vpc"},
+        {"address": "data.google_project.current", "mode": "data", "type": "google_project", "name": "current"}
+      ],
+      "child_modules": [
+        {
+          "address": "module.subnets",
+          "resources": [
+            {"address": "module.subnets.google_compute_subnetwork.subnet[\"web\"]", "mode": "managed", "type": "google_compute_subnetwork", "name": "subnet"},
+            {"address": "module.subnets.google_compute_subnetwork.subnet[\"db\"]", "mode": "managed", "type": "google_compute_subnetwork", "name": "subnet"}
+          ],
+          "child_modules": [
+            {"resources": [{"address": "module.subnets.module.nat.google_compute_subnetwork.proxy", "mode": "managed", "type": "google_compute_subnetwork", "name": "proxy"}]}
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+func TestCountManagedResources(t *testing.T) {
+	state, err := parseStateJSON([]byte(sampleState))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := state.resources()
+
+	cases := map[string]int{
+		"google_compute_subnetwork": 3,
+		"google_compute_network":    1,
+		"google_project":            0,
+		"google_storage_bucket":     0,
+	}
+	for resourceType, want := range cases {
+		if got := countManagedResources(resources, resourceType); got != want {
+			t.Errorf("countManagedResources(%s) = %d, want %d", resourceType, got, want)
+		}
+	}
+
+	empty, err := parseStateJSON([]byte(`{"format_version":"1.0"}`))
+	if err != nil || len(empty.resources()) != 0 {
+		t.Errorf("empty state: %v, %v", empty.resources(), err)
+	}
+}
+
+func TestAssertResourceCountStubbed(t *testing.T) {
+	stubTerraform(t, func(args ...string) (string, error) { return sampleState, nil })
+	AssertResourceCount(t, &terraform.Options{}, "google_compute_subnetwork", 3)
+}
+
+func TestShowStateReadsStdoutOnly(t *testing.T) {
+	stubTerraform(t, func(args ...string) (string, error) { return sampleState, nil })
+	terraformRunner = func(*testing.T, *terraform.Options, ...string) (string, error) {
+		return "Warning: Deprecated attribute\n" + sampleState, nil
+	}
+	AssertResourceCount(t, &terraform.Options{}, "google_compute_subnetwork", 3)
+}
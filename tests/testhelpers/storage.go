@@ -0,0 +1,136 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// storageBucket mirrors the fields of `gcloud storage buckets describe`
+// output used by the assertions in this file.
+type storageBucket struct {
+	Name         string `json:"name"`
+	Location     string `json:"location"`
+	LocationType string `json:"location_type"`
+	CORSConfig   []struct {
+		Origin []string `json:"origin"`
+		Method []string `json:"method"`
+	} `json:"cors_config"`
+	LoggingConfig *struct {
+		LogBucket       string `json:"logBucket"`
+		LogObjectPrefix string `json:"logObjectPrefix"`
+	} `json:"logging_config"`
+}
+
+func describeBucket(t *testing.T, projectID, bucket string) storageBucket {
+	t.Helper()
+
+	var b storageBucket
+	if err := gcloudJSON(&b, "storage", "buckets", "describe", "gs://"+bucket, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe bucket %s: %v", bucket, err)
+	}
+	return b
+}
+
+// corsOriginsAndMethods returns every origin and (upper-cased) method
+// allowed by any of the bucket's CORS entries.
+func corsOriginsAndMethods(b storageBucket) (origins, methods []string) {
+	for _, entry := range b.CORSConfig {
+		origins = append(origins, entry.Origin...)
+		for _, m := range entry.Method {
+			methods = append(methods, strings.ToUpper(m))
+		}
+	}
+	return origins, methods
+}
+
+// checkBucketCORS returns the expected origins and methods the bucket's CORS
+// configuration does not allow.
+func checkBucketCORS(b storageBucket, expectedOrigins, expectedMethods []string) []string {
+	origins, methods := corsOriginsAndMethods(b)
+
+	wantMethods := make([]string, len(expectedMethods))
+	for i, m := range expectedMethods {
+		wantMethods[i] = strings.ToUpper(m)
+	}
+
+	var problems []string
+	for _, o := range missingFrom(expectedOrigins, origins) {
+		problems = append(problems, fmt.Sprintf("origin %s is not allowed", o))
+	}
+	for _, m := range missingFrom(wantMethods, methods) {
+		problems = append(problems, fmt.Sprintf("method %s is not allowed", m))
+	}
+	return problems
+}
+
+// AssertBucketCORS fails the test if the bucket's CORS configuration does
+// not allow every expected origin and method.
+func AssertBucketCORS(t *testing.T, projectID, bucket string, expectedOrigins []string, expectedMethods []string) {
+	t.Helper()
+
+	b := describeBucket(t, projectID, bucket)
+	origins, methods := corsOriginsAndMethods(b)
+	t.Logf("Bucket %s CORS origins=[%s] methods=[%s]", bucket, strings.Join(origins, ", "), strings.Join(methods, ", "))
+
+	for _, p := range checkBucketCORS(b, expectedOrigins, expectedMethods) {
+		t.Errorf("Bucket %s: %s", bucket, p)
+	}
+}
+
+// checkBucketAccessLogging returns an error unless the bucket writes usage
+// and storage logs to expectLogBucket. Either name may carry a gs:// prefix.
+func checkBucketAccessLogging(b storageBucket, expectLogBucket string) error {
+	if b.LoggingConfig == nil || b.LoggingConfig.LogBucket == "" {
+		return fmt.Errorf("access logging is not configured")
+	}
+	actual := strings.TrimPrefix(b.LoggingConfig.LogBucket, "gs://")
+	if want := strings.TrimPrefix(expectLogBucket, "gs://"); actual != want {
+		return fmt.Errorf("access logs go to bucket %s, want %s", actual, want)
+	}
+	return nil
+}
+
+// AssertBucketAccessLogging fails the test unless the bucket's usage and
+// storage logs are delivered to expectLogBucket.
+func AssertBucketAccessLogging(t *testing.T, projectID, bucket, expectLogBucket string) {
+	t.Helper()
+
+	b := describeBucket(t, projectID, bucket)
+	if b.LoggingConfig != nil {
+		t.Logf("Bucket %s access logging: log bucket=%s prefix=%q", bucket, b.LoggingConfig.LogBucket, b.LoggingConfig.LogObjectPrefix)
+	} else {
+		t.Logf("Bucket %s has no access logging", bucket)
+	}
+	if err := checkBucketAccessLogging(b, expectLogBucket); err != nil {
+		t.Errorf("Bucket %s: %v", bucket, err)
+	}
+}
+
+// normalizeLocationType maps location type spellings ("dual-region",
+// "DUAL_REGION") to the upper-case hyphenated form, e.g. DUAL-REGION.
+func normalizeLocationType(locationType string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(locationType)), "_", "-")
+}
+
+// checkBucketLocationType returns an error if the bucket's location type is
+// not expectedType (REGION, DUAL-REGION or MULTI-REGION).
+func checkBucketLocationType(b storageBucket, expectedType string) error {
+	actual := normalizeLocationType(b.LocationType)
+	if want := normalizeLocationType(expectedType); actual != want {
+		return fmt.Errorf("location type is %s (%s), want %s", actual, b.Location, want)
+	}
+	return nil
+}
+
+// AssertBucketLocationType fails the test unless the bucket's location type
+// is expectedType: REGION, DUAL-REGION or MULTI-REGION.
+func AssertBucketLocationType(t *testing.T, projectID, bucket, expectedType string) {
+	t.Helper()
+
+	b := describeBucket(t, projectID, bucket)
+	t.Logf("Bucket %s location=%s type=%s", bucket, b.Location, normalizeLocationType(b.LocationType))
+	if err := checkBucketLocationType(b, expectedType); err != nil {
+		t.Errorf("Bucket %s: %v", bucket, err)
+	}
+}
@@ -0,0 +1,79 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodeBucket(t *testing.T, raw string) storageBucket {
+	t.Helper()
+	var b storageBucket
+	if err := json.Unmarshal([]byte(raw), &b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCheckBucketCORS(t *testing.T) {
+	b := decodeBucket(t, `{"name":"assets","cors_config":[
+		{"origin":["https://app.example.com"],"method":["get","HEAD"]},
+		{"origin":["https://admin.example.com"],"method":["PUT"]}]}`)
+
+	if got := checkBucketCORS(b, []string{"https://app.example.com", "https://admin.example.com"}, []string{"GET", "put"}); len(got) != 0 {
+		t.Errorf("checkBucketCORS() = %v, want no problems", got)
+	}
+
+	got := checkBucketCORS(b, []string{"https://app.example.com", "https://evil.example.com"}, []string{"GET", "DELETE"})
+	want := []string{"origin https://evil.example.com is not allowed", "method DELETE is not allowed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkBucketCORS() = %v, want %v", got, want)
+	}
+
+	if got := checkBucketCORS(decodeBucket(t, `{"name":"assets"}`), []string{"*"}, nil); len(got) != 1 {
+		t.Errorf("bucket without CORS: got %v, want one problem", got)
+	}
+}
+
+func TestCheckBucketAccessLogging(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"matches", `{"logging_config":{"logBucket":"audit-logs","logObjectPrefix":"assets"}}`, "audit-logs", false},
+		{"gs prefix", `{"logging_config":{"logBucket":"audit-logs"}}`, "gs://audit-logs", false},
+		{"other bucket", `{"logging_config":{"logBucket":"scratch"}}`, "audit-logs", true},
+		{"unset", `{"name":"assets"}`, "audit-logs", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkBucketAccessLogging(decodeBucket(t, tc.raw), tc.want); (err != nil) != tc.wantErr {
+				t.Errorf("checkBucketAccessLogging() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckBucketLocationType(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected string
+		wantErr  bool
+	}{
+		{"region", `{"location":"EUROPE-WEST1","location_type":"region"}`, "REGION", false},
+		{"dual-region", `{"location":"EUR4","location_type":"dual-region"}`, "DUAL-REGION", false},
+		{"underscore spelling", `{"location":"EU","location_type":"multi-region"}`, "multi_region", false},
+		{"multi vs region", `{"location":"US","location_type":"multi-region"}`, "REGION", true},
+		{"unset", `{}`, "REGION", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkBucketLocationType(decodeBucket(t, tc.raw), tc.expected); (err != nil) != tc.wantErr {
+				t.Errorf("checkBucketLocationType() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package testhelpers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// subnetwork mirrors the fields of a VPC subnet used by the assertions in
+// this file.
+type subnetwork struct {
+	Name        string `json:"name"`
+	IPCidrRange string `json:"ipCidrRange"`
+	Purpose     string `json:"purpose"`
+	Role        string `json:"role"`
+	LogConfig   *struct {
+		Enable              bool   `json:"enable"`
+		AggregationInterval string `json:"aggregationInterval"`
+	} `json:"logConfig"`
+}
+
+func describeSubnet(t *testing.T, projectID, region, subnet string) subnetwork {
+	t.Helper()
+
+	var sn subnetwork
+	if err := gcloudJSON(&sn, "compute", "networks", "subnets", "describe", subnet,
+		"--region", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe subnet %s: %v", subnet, err)
+	}
+	return sn
+}
+
+// checkProxyOnlySubnet returns the reasons the subnet cannot serve as the
+// active proxy-only subnet for regional load balancers.
+func checkProxyOnlySubnet(sn subnetwork) []string {
+	var problems []string
+	if sn.Purpose != "REGIONAL_MANAGED_PROXY" {
+		problems = append(problems, fmt.Sprintf("purpose is %q, want REGIONAL_MANAGED_PROXY", sn.Purpose))
+	}
+	if sn.Role != "ACTIVE" {
+		problems = append(problems, fmt.Sprintf("role is %q, want ACTIVE", sn.Role))
+	}
+	return problems
+}
+
+// AssertProxyOnlySubnet fails the test unless the subnet is an ACTIVE
+// REGIONAL_MANAGED_PROXY subnet.
+func AssertProxyOnlySubnet(t *testing.T, projectID, region, subnet string) {
+	t.Helper()
+
+	sn := describeSubnet(t, projectID, region, subnet)
+	t.Logf("Subnet %s purpose=%s role=%s", subnet, sn.Purpose, sn.Role)
+	for _, p := range checkProxyOnlySubnet(sn) {
+		t.Errorf("Subnet %s: %s", subnet, p)
+	}
+}
+
+// checkFlowLogAggregation returns an error unless flow logs are enabled with
+// the expected aggregation interval (e.g. INTERVAL_5_SEC).
+func checkFlowLogAggregation(sn subnetwork, expectedInterval string) error {
+	if sn.LogConfig == nil || !sn.LogConfig.Enable {
+		return fmt.Errorf("flow logs are not enabled")
+	}
+	if sn.LogConfig.AggregationInterval != expectedInterval {
+		return fmt.Errorf("flow log aggregation interval is %s, want %s", sn.LogConfig.AggregationInterval, expectedInterval)
+	}
+	return nil
+}
+
+// AssertFlowLogAggregation fails the test unless the subnet's VPC flow logs
+// are enabled and aggregated at expectedInterval.
+func AssertFlowLogAggregation(t *testing.T, projectID, region, subnet string, expectedInterval string) {
+	t.Helper()
+
+	sn := describeSubnet(t, projectID, region, subnet)
+	if sn.LogConfig != nil {
+		t.Logf("Subnet %s flow logs enabled=%t aggregationInterval=%s", subnet, sn.LogConfig.Enable, sn.LogConfig.AggregationInterval)
+	}
+	if err := checkFlowLogAggregation(sn, expectedInterval); err != nil {
+		t.Errorf("Subnet %s: %v", subnet, err)
+	}
+}
+
+// subnetReservedAddresses is the number of addresses GCP reserves in every
+// primary IPv4 range: network, gateway, second-to-last and broadcast.
+const subnetReservedAddresses = 4
+
+// usableAddresses returns the number of assignable addresses in an IPv4
+// CIDR range.
+func usableAddresses(cidr string) (int, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0, err
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		return 0, fmt.Errorf("%s is not an IPv4 range", cidr)
+	}
+	total := 1 << (bits - ones)
+	if total <= subnetReservedAddresses {
+		return 0, fmt.Errorf("%s has no usable addresses", cidr)
+	}
+	return total - subnetReservedAddresses, nil
+}
+
+// subnetUtilization returns the number of distinct ips inside cidr and the
+// percentage of its usable addresses they take up.
+func subnetUtilization(cidr string, ips []string) (used int, percent float64, err error) {
+	usable, err := usableAddresses(cidr)
+	if err != nil {
+		return 0, 0, err
+	}
+	_, ipNet, _ := net.ParseCIDR(cidr)
+	seen := make(map[string]bool, len(ips))
+	for _, s := range ips {
+		if ip := net.ParseIP(s); ip != nil && ipNet.Contains(ip) {
+			seen[ip.String()] = true
+		}
+	}
+	used = len(seen)
+	return used, float64(used) * 100 / float64(usable), nil
+}
+
+// subnetIPsInUse lists the internal addresses reserved in the subnet and the
+// primary addresses of instances attached to it.
+func subnetIPsInUse(t *testing.T, projectID, region, subnet string) []string {
+	t.Helper()
+
+	var addresses []struct {
+		Address    string `json:"address"`
+		Subnetwork string `json:"subnetwork"`
+	}
+	if err := gcloudJSON(&addresses, "compute", "addresses", "list", "--regions", region, "--project", projectID); err != nil {
+		t.Fatalf("Failed to list addresses in %s: %v", region, err)
+	}
+	var instances []struct {
+		NetworkInterfaces []struct {
+			NetworkIP  string `json:"networkIP"`
+			Subnetwork string `json:"subnetwork"`
+		} `json:"networkInterfaces"`
+	}
+	if err := gcloudJSON(&instances, "compute", "instances", "list", "--project", projectID); err != nil {
+		t.Fatalf("Failed to list instances in %s: %v", projectID, err)
+	}
+
+	suffix := "/regions/" + region + "/subnetworks/" + subnet
+	inSubnet := func(url string) bool { return strings.HasSuffix(url, suffix) }
+	var ips []string
+	for _, a := range addresses {
+		if inSubnet(a.Subnetwork) {
+			ips = append(ips, a.Address)
+		}
+	}
+	for _, inst := range instances {
+		for _, nic := range inst.NetworkInterfaces {
+			if inSubnet(nic.Subnetwork) {
+				ips = append(ips, nic.NetworkIP)
+			}
+		}
+	}
+	return ips
+}
+
+// AssertSubnetUtilization fails the test if the addresses in use in the
+// subnet's primary range exceed maxPercent of its usable addresses. In-use
+// addresses are reserved internal addresses and instance primary IPs.
+func AssertSubnetUtilization(t *testing.T, projectID, region, subnet string, maxPercent float64) {
+	t.Helper()
+
+	sn := describeSubnet(t, projectID, region, subnet)
+	used, percent, err := subnetUtilization(sn.IPCidrRange, subnetIPsInUse(t, projectID, region, subnet))
+	if err != nil {
+		t.Fatalf("Subnet %s: %v", subnet, err)
+	}
+	usable, _ := usableAddresses(sn.IPCidrRange)
+	t.Logf("Subnet %s (%s) uses %d of %d addresses (%.1f%%)", subnet, sn.IPCidrRange, used, usable, percent)
+	if percent > maxPercent {
+		t.Errorf("Subnet %s is %.1f%% utilized, above the %.1f%% limit", subnet, percent, maxPercent)
+	}
+}
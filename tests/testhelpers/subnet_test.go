@@ -0,0 +1,103 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCheckProxyOnlySubnet(t *testing.T) {
+	cases := []struct {
+		name         string
+		subnet       subnetwork
+		wantProblems int
+	}{
+		{"active proxy-only", subnetwork{Purpose: "REGIONAL_MANAGED_PROXY", Role: "ACTIVE"}, 0},
+		{"backup proxy-only", subnetwork{Purpose: "REGIONAL_MANAGED_PROXY", Role: "BACKUP"}, 1},
+		{"private subnet", subnetwork{Purpose: "PRIVATE"}, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkProxyOnlySubnet(tc.subnet); len(got) != tc.wantProblems {
+				t.Errorf("checkProxyOnlySubnet() = %v, want %d problem(s)", got, tc.wantProblems)
+			}
+		})
+	}
+}
+
+func TestCheckFlowLogAggregation(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{"matching interval", `{"logConfig":{"enable":true,"aggregationInterval":"INTERVAL_5_SEC"}}`, false},
+		{"different interval", `{"logConfig":{"enable":true,"aggregationInterval":"INTERVAL_10_MIN"}}`, true},
+		{"disabled", `{"logConfig":{"enable":false,"aggregationInterval":"INTERVAL_5_SEC"}}`, true},
+		{"no log config", `{}`, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sn subnetwork
+			if err := json.Unmarshal([]byte(tc.raw), &sn); err != nil {
+				t.Fatal(err)
+			}
+			if err := checkFlowLogAggregation(sn, "INTERVAL_5_SEC"); (err != nil) != tc.wantErr {
+				t.Errorf("checkFlowLogAggregation() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsableAddresses(t *testing.T) {
+	cases := map[string]int{
+		"10.0.0.0/24": 252,
+		"10.0.0.0/20": 4092,
+		"10.0.0.0/29": 4,
+		"10.8.0.0/16": 65532,
+	}
+	for cidr, want := range cases {
+		if got, err := usableAddresses(cidr); err != nil || got != want {
+			t.Errorf("usableAddresses(%s) = %d, %v, want %d", cidr, got, err, want)
+		}
+	}
+	for _, cidr := range []string{"10.0.0.0/30", "fd20::/64", "garbage"} {
+		if _, err := usableAddresses(cidr); err == nil {
+			t.Errorf("usableAddresses(%s): expected an error", cidr)
+		}
+	}
+}
+
+func TestSubnetUtilization(t *testing.T) {
+	cases := []struct {
+		name        string
+		cidr        string
+		ips         []string
+		wantUsed    int
+		wantPercent float64
+	}{
+		{"empty", "10.0.0.0/24", nil, 0, 0},
+		{"quarter of /29", "10.0.0.0/29", []string{"10.0.0.2"}, 1, 25},
+		{"duplicates and outsiders ignored", "10.0.0.0/29", []string{"10.0.0.2", "10.0.0.2", "10.0.1.5", "bad"}, 1, 25},
+		{"full /29", "10.0.0.0/29", []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}, 4, 100},
+		{"half of /24", "10.0.0.0/24", hostRange("10.0.0.", 2, 127), 126, 50},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			used, percent, err := subnetUtilization(tc.cidr, tc.ips)
+			if err != nil || used != tc.wantUsed || math.Abs(percent-tc.wantPercent) > 1e-9 {
+				t.Errorf("subnetUtilization() = %d, %.2f, %v, want %d, %.2f", used, percent, err, tc.wantUsed, tc.wantPercent)
+			}
+		})
+	}
+}
+
+// hostRange returns prefix+first ... prefix+last.
+func hostRange(prefix string, first, last int) []string {
+	var ips []string
+	for i := first; i <= last; i++ {
+		ips = append(ips, fmt.Sprintf("%s%d", prefix, i))
+	}
+	return ips
+}
@@ -0,0 +1,185 @@
+package testhelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// terraformRunner runs a terraform command in opts.TerraformDir and returns
+// its combined output. Unit tests replace it to simulate terraform.
+var terraformRunner = func(t *testing.T, opts *terraform.Options, args ...string) (string, error) {
+	return terraform.RunTerraformCommandE(t, opts, args...)
+}
+
+// terraformStdoutRunner is terraformRunner for commands whose output is
+// parsed, such as show -json. It returns stdout only, so warnings or TF_LOG
+// output on stderr can't corrupt it. Unit tests replace it.
+var terraformStdoutRunner = func(t *testing.T, opts *terraform.Options, args ...string) (string, error) {
+	return terraform.RunTerraformCommandAndGetStdoutE(t, opts, args...)
+}
+
+// planRunner runs terraform init and plan for opts. Unit tests replace it.
+var planRunner = func(t *testing.T, opts *terraform.Options) (string, error) {
+	return terraform.InitAndPlanE(t, opts)
+}
+
+// now is the clock used for timing terraform commands. Unit tests replace it.
+var now = time.Now
+
+var (
+	// stateLockAcquireDelay gives the first apply time to take the lock
+	// before the contending command starts.
+	stateLockAcquireDelay = 10 * time.Second
+	// stateLockTimeout bounds how long the contending command may take to
+	// report the lock conflict. Zero uses Config.DefaultTimeout.
+	stateLockTimeout time.Duration
+)
+
+// stateLockErrorMarkers are substrings terraform prints when it cannot take
+// the state lock.
+var stateLockErrorMarkers = []string{
+	"Error acquiring the state lock",
+	"Error locking state",
+}
+
+// isStateLockError reports whether terraform output describes a lock conflict.
+func isStateLockError(output string) bool {
+	for _, marker := range stateLockErrorMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLockContention returns an error unless the contending command failed
+// because the state was locked.
+func checkLockContention(output string, err error) error {
+	if err == nil {
+		return fmt.Errorf("concurrent terraform command succeeded while the state should have been locked")
+	}
+	if !isStateLockError(output) && !isStateLockError(err.Error()) {
+		return fmt.Errorf("concurrent terraform command failed, but not with a state lock error: %v", err)
+	}
+	return nil
+}
+
+type terraformResult struct {
+	output string
+	err    error
+}
+
+// stateLockArgs returns the arguments for the lock-holding apply and the
+// contending plan. Locking is set through copies of opts rather than raw
+// flags, because terraform.FormatArgs appends -lock and -lock-timeout from
+// opts and terraform honours the last occurrence.
+func stateLockArgs(opts *terraform.Options) (holder, contender []string) {
+	holderOpts := *opts
+	holderOpts.Lock = true
+	contenderOpts := holderOpts
+	contenderOpts.LockTimeout = "0s"
+	return terraform.FormatArgs(&holderOpts, "apply", "-input=false", "-auto-approve"),
+		terraform.FormatArgs(&contenderOpts, "plan", "-input=false")
+}
+
+// AssertStateLocking starts an apply and, while it holds the state lock,
+// runs a concurrent plan that must fail with a lock error within
+// Config.DefaultTimeout. It returns only after both commands have finished,
+// so neither uses t once the helper has returned.
+func AssertStateLocking(t *testing.T, opts *terraform.Options) {
+	t.Helper()
+
+	holderArgs, contenderArgs := stateLockArgs(opts)
+
+	holder := make(chan terraformResult, 1)
+	go func() {
+		out, err := terraformRunner(t, opts, holderArgs...)
+		holder <- terraformResult{out, err}
+	}()
+
+	time.Sleep(stateLockAcquireDelay)
+
+	contender := make(chan terraformResult, 1)
+	go func() {
+		out, err := terraformRunner(t, opts, contenderArgs...)
+		contender <- terraformResult{out, err}
+	}()
+
+	timeout := Config.timeout(stateLockTimeout)
+	select {
+	case res := <-contender:
+		if err := checkLockContention(res.output, res.err); err != nil {
+			t.Error(err)
+		} else {
+			t.Log("Concurrent plan was rejected with a state lock error")
+		}
+		contender = nil
+	case <-time.After(timeout):
+		t.Errorf("Concurrent plan did not report a state lock error within %s", timeout)
+	}
+
+	if res := <-holder; res.err != nil {
+		t.Errorf("Lock-holding apply failed: %v", res.err)
+	}
+	if contender != nil {
+		// The plan uses -lock-timeout=0s, so it ends at the latest once the
+		// apply has released the lock.
+		<-contender
+	}
+}
+
+// checkPlanDuration returns an error if a plan took longer than max. A zero
+// max disables the check.
+func checkPlanDuration(d, max time.Duration) error {
+	if max > 0 && d > max {
+		return fmt.Errorf("terraform plan took %s, exceeding the %s limit", d.Round(time.Millisecond), max)
+	}
+	return nil
+}
+
+// MeasurePlanTime times terraform init and plan for opts and returns the
+// duration. The test fails if the plan errors or, when Config.MaxPlanDuration
+// is set, if it takes longer than that.
+func MeasurePlanTime(t *testing.T, opts *terraform.Options) time.Duration {
+	t.Helper()
+
+	start := now()
+	_, err := planRunner(t, opts)
+	elapsed := now().Sub(start)
+
+	t.Logf("terraform plan in %s took %s", opts.TerraformDir, elapsed.Round(time.Millisecond))
+	if err != nil {
+		t.Errorf("terraform plan failed: %v", err)
+	}
+	if err := checkPlanDuration(elapsed, Config.MaxPlanDuration); err != nil {
+		t.Error(err)
+	}
+	return elapsed
+}
+
+// checkWorkspace returns an error if `terraform workspace show` output does
+// not name the expected workspace.
+func checkWorkspace(output, expected string) error {
+	if actual := strings.TrimSpace(output); actual != expected {
+		return fmt.Errorf("terraform workspace is %q, want %q", actual, expected)
+	}
+	return nil
+}
+
+// AssertWorkspace fails the test unless the selected terraform workspace for
+// opts is expected.
+func AssertWorkspace(t *testing.T, opts *terraform.Options, expected string) {
+	t.Helper()
+
+	out, err := terraformRunner(t, opts, "workspace", "show")
+	if err != nil {
+		t.Fatalf("terraform workspace show failed: %v", err)
+	}
+	if err := checkWorkspace(out, expected); err != nil {
+		t.Error(err)
+	}
+}
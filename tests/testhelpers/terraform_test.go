@@ -0,0 +1,154 @@
+package testhelpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// stubTerraform replaces terraformRunner and terraformStdoutRunner for the
+// duration of the test.
+func stubTerraform(t *testing.T, fn func(args ...string) (string, error)) {
+	t.Helper()
+	orig, origStdout := terraformRunner, terraformStdoutRunner
+	terraformRunner = func(_ *testing.T, _ *terraform.Options, args ...string) (string, error) {
+		return fn(args...)
+	}
+	terraformStdoutRunner = terraformRunner
+	t.Cleanup(func() { terraformRunner, terraformStdoutRunner = orig, origStdout })
+}
+
+func TestCheckLockContention(t *testing.T) {
+	cases := []struct {
+		name    string
+		output  string
+		err     error
+		wantErr bool
+	}{
+		{"lock error", "Error: Error acquiring the state lock\n\nLock Info: ...", errors.New("exit status 1"), false},
+		{"lock error in err", "", errors.New("Error locking state: already locked"), false},
+		{"succeeded", "No changes.", nil, true},
+		{"other failure", "Error: Invalid provider configuration", errors.New("exit status 1"), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkLockContention(tc.output, tc.err); (err != nil) != tc.wantErr {
+				t.Errorf("checkLockContention() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssertStateLockingSimulated(t *testing.T) {
+	origDelay, origTimeout := stateLockAcquireDelay, stateLockTimeout
+	stateLockAcquireDelay, stateLockTimeout = time.Millisecond, time.Second
+	t.Cleanup(func() { stateLockAcquireDelay, stateLockTimeout = origDelay, origTimeout })
+
+	released := make(chan struct{})
+	stubTerraform(t, func(args ...string) (string, error) {
+		switch args[0] {
+		case "apply":
+			<-released
+			return "Apply complete!", nil
+		case "plan":
+			defer close(released)
+			if lastFlag(args, "lock") != "true" || lastFlag(args, "lock-timeout") != "0s" {
+				t.Errorf("plan args = %v, want -lock=true -lock-timeout=0s", args)
+			}
+			return "Error: Error acquiring the state lock", errors.New("exit status 1")
+		}
+		return "", nil
+	})
+
+	AssertStateLocking(t, &terraform.Options{})
+}
+
+// lastFlag returns the value of the last -name=value argument, as terraform
+// honours the last occurrence of a flag.
+func lastFlag(args []string, name string) string {
+	value := ""
+	for _, a := range args {
+		if v, ok := strings.CutPrefix(a, "-"+name+"="); ok {
+			value = v
+		}
+	}
+	return value
+}
+
+func TestStateLockArgs(t *testing.T) {
+	opts := &terraform.Options{NoColor: true, LockTimeout: "5m", Vars: map[string]interface{}{"region": "europe-west1"}}
+	holder, contender := stateLockArgs(opts)
+
+	if holder[0] != "apply" || lastFlag(holder, "lock") != "true" || lastFlag(holder, "lock-timeout") != "5m" {
+		t.Errorf("holder args = %v, want apply with -lock=true", holder)
+	}
+	if contender[0] != "plan" || lastFlag(contender, "lock") != "true" || lastFlag(contender, "lock-timeout") != "0s" {
+		t.Errorf("contender args = %v, want plan with -lock=true -lock-timeout=0s", contender)
+	}
+	if opts.Lock || opts.LockTimeout != "5m" {
+		t.Errorf("stateLockArgs() modified opts: %+v", opts)
+	}
+}
+
+func TestCheckPlanDuration(t *testing.T) {
+	cases := []struct {
+		name    string
+		elapsed time.Duration
+		max     time.Duration
+		wantErr bool
+	}{
+		{"under limit", 30 * time.Second, time.Minute, false},
+		{"at limit", time.Minute, time.Minute, false},
+		{"over limit", 61 * time.Second, time.Minute, true},
+		{"no limit", time.Hour, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkPlanDuration(tc.elapsed, tc.max); (err != nil) != tc.wantErr {
+				t.Errorf("checkPlanDuration() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMeasurePlanTimeUsesInjectedClock(t *testing.T) {
+	origNow, origPlan := now, planRunner
+	t.Cleanup(func() { now, planRunner = origNow, origPlan })
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ticks := []time.Time{start, start.Add(42 * time.Second)}
+	now = func() time.Time {
+		next := ticks[0]
+		ticks = ticks[1:]
+		return next
+	}
+	planRunner = func(*testing.T, *terraform.Options) (string, error) { return "No changes.", nil }
+
+	if got := MeasurePlanTime(t, &terraform.Options{}); got != 42*time.Second {
+		t.Errorf("MeasurePlanTime() = %s, want 42s", got)
+	}
+}
+
+func TestCheckWorkspace(t *testing.T) {
+	if err := checkWorkspace("dev\n", "dev"); err != nil {
+		t.Errorf("matching workspace: %v", err)
+	}
+	if err := checkWorkspace("default\n", "prod"); err == nil {
+		t.Error("expected mismatch for default vs prod")
+	}
+}
+
+func TestAssertWorkspaceStubbed(t *testing.T) {
+	var got []string
+	stubTerraform(t, func(args ...string) (string, error) {
+		got = args
+		return "staging\n", nil
+	})
+	AssertWorkspace(t, &terraform.Options{}, "staging")
+	if strings.Join(got, " ") != "workspace show" {
+		t.Errorf("terraform args = %v, want workspace show", got)
+	}
+}
@@ -0,0 +1,245 @@
+package testhelpers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// testProjectEnv names the project RunVPCCases deploys into.
+const testProjectEnv = "GOOGLE_CLOUD_PROJECT"
+
+var (
+	// VPCModuleDir and SubnetsModuleDir locate the networking modules applied
+	// by RunVPCCases, relative to a test package under tests/.
+	VPCModuleDir     = "../../infrastructure/modules/networking/vpc"
+	SubnetsModuleDir = "../../infrastructure/modules/networking/subnets"
+)
+
+// VPCTestCase is one configuration exercised by RunVPCCases: a network with
+// the given routing mode and a single subnet of CIDR in Region.
+type VPCTestCase struct {
+	Name        string
+	CIDR        string
+	Region      string
+	RoutingMode string // REGIONAL (the default when empty) or GLOBAL
+}
+
+// vpcRun is a VPCTestCase resolved into the subtest name and the names of
+// the resources it creates.
+type vpcRun struct {
+	name        string
+	network     string
+	subnet      string
+	cidr        string
+	region      string
+	routingMode string
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// vpcResourceName builds a GCE-compliant resource name from a case name and
+// the run suffix.
+func vpcResourceName(caseName, kind, suffix string) string {
+	slug := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(caseName), "-"), "-")
+	base := "vpc-test-" + slug
+	if max := 63 - len(kind) - len(suffix) - 2; len(base) > max {
+		base = strings.TrimRight(base[:max], "-")
+	}
+	return base + "-" + kind + "-" + suffix
+}
+
+// expandVPCCases validates the cases and resolves each into a run. Case
+// names must be unique since they become subtest names.
+func expandVPCCases(cases []VPCTestCase, suffix string) ([]vpcRun, error) {
+	seen := make(map[string]bool, len(cases))
+	runs := make([]vpcRun, 0, len(cases))
+	for i, tc := range cases {
+		if tc.Name == "" {
+			return nil, fmt.Errorf("case %d has no name", i)
+		}
+		if seen[tc.Name] {
+			return nil, fmt.Errorf("duplicate case name %q", tc.Name)
+		}
+		seen[tc.Name] = true
+
+		if _, _, err := net.ParseCIDR(tc.CIDR); err != nil {
+			return nil, fmt.Errorf("case %q: invalid CIDR %q", tc.Name, tc.CIDR)
+		}
+		if tc.Region == "" {
+			return nil, fmt.Errorf("case %q has no region", tc.Name)
+		}
+		mode := strings.ToUpper(tc.RoutingMode)
+		if mode == "" {
+			mode = "REGIONAL"
+		}
+		if mode != "REGIONAL" && mode != "GLOBAL" {
+			return nil, fmt.Errorf("case %q: routing mode %q is not REGIONAL or GLOBAL", tc.Name, tc.RoutingMode)
+		}
+
+		runs = append(runs, vpcRun{
+			name:        tc.Name,
+			network:     vpcResourceName(tc.Name, "net", suffix),
+			subnet:      vpcResourceName(tc.Name, "sub", suffix),
+			cidr:        tc.CIDR,
+			region:      tc.Region,
+			routingMode: mode,
+		})
+	}
+	return runs, nil
+}
+
+// validateCredentials checks that the active gcloud credentials can read
+// the project.
+func validateCredentials(projectID string) error {
+	var project struct {
+		ProjectID      string `json:"projectId"`
+		LifecycleState string `json:"lifecycleState"`
+	}
+	if err := gcloudJSON(&project, "projects", "describe", projectID); err != nil {
+		return err
+	}
+	if project.LifecycleState != "ACTIVE" {
+		return fmt.Errorf("project %s is %s", projectID, project.LifecycleState)
+	}
+	return nil
+}
+
+// vpcNetwork mirrors the fields of a VPC network used by the assertions in
+// this file.
+type vpcNetwork struct {
+	Name          string `json:"name"`
+	MTU           int    `json:"mtu"`
+	RoutingConfig struct {
+		RoutingMode string `json:"routingMode"`
+	} `json:"routingConfig"`
+}
+
+func describeNetwork(t *testing.T, projectID, network string) vpcNetwork {
+	t.Helper()
+
+	var n vpcNetwork
+	if err := gcloudJSON(&n, "compute", "networks", "describe", network, "--project", projectID); err != nil {
+		t.Fatalf("Failed to describe network %s: %v", network, err)
+	}
+	return n
+}
+
+// checkVPCCase returns the differences between the deployed network and
+// subnet and what the run asked for.
+func checkVPCCase(run vpcRun, routingMode string, sn subnetwork) []string {
+	var problems []string
+	if routingMode != run.routingMode {
+		problems = append(problems, fmt.Sprintf("routing mode is %s, want %s", routingMode, run.routingMode))
+	}
+	if sn.IPCidrRange != run.cidr {
+		problems = append(problems, fmt.Sprintf("subnet %s range is %s, want %s", run.subnet, sn.IPCidrRange, run.cidr))
+	}
+	return problems
+}
+
+// runVPCCase applies the VPC and subnets modules for one run, checks the
+// result and destroys both. Unit tests replace it.
+var runVPCCase = func(t *testing.T, projectID string, run vpcRun) {
+	vpcOpts := &terraform.Options{
+		TerraformDir: VPCModuleDir,
+		NoColor:      true,
+		Vars: map[string]interface{}{
+			"project_id":   projectID,
+			"network_name": run.network,
+			"routing_mode": run.routingMode,
+		},
+	}
+	defer terraform.Destroy(t, vpcOpts)
+	terraform.InitAndApply(t, vpcOpts)
+
+	subnetOpts := &terraform.Options{
+		TerraformDir: SubnetsModuleDir,
+		NoColor:      true,
+		Vars: map[string]interface{}{
+			"project_id":   projectID,
+			"network_name": run.network,
+			"subnets": []map[string]interface{}{{
+				"subnet_name":           run.subnet,
+				"subnet_ip":             run.cidr,
+				"subnet_region":         run.region,
+				"subnet_private_access": true,
+			}},
+		},
+	}
+	defer terraform.Destroy(t, subnetOpts)
+	terraform.InitAndApply(t, subnetOpts)
+
+	network := describeNetwork(t, projectID, run.network)
+	sn := describeSubnet(t, projectID, run.region, run.subnet)
+	t.Logf("Network %s routing mode=%s, subnet %s range=%s", run.network, network.RoutingConfig.RoutingMode, run.subnet, sn.IPCidrRange)
+
+	for _, p := range checkVPCCase(run, network.RoutingConfig.RoutingMode, sn) {
+		t.Errorf("Network %s: %s", run.network, p)
+	}
+}
+
+// RunVPCCases runs each case as a subtest that applies the VPC and subnets
+// modules into the project named by GOOGLE_CLOUD_PROJECT, checks the routing
+// mode and subnet range, and destroys what it created. Credentials are
+// validated once before any case runs. Cases run sequentially because they
+// share the modules' local state.
+func RunVPCCases(t *testing.T, cases []VPCTestCase) {
+	t.Helper()
+
+	projectID := os.Getenv(testProjectEnv)
+	if projectID == "" {
+		t.Fatalf("%s must be set to run VPC cases", testProjectEnv)
+	}
+	runs, err := expandVPCCases(cases, strconv.FormatInt(now().Unix(), 36))
+	if err != nil {
+		t.Fatalf("Invalid VPC test cases: %v", err)
+	}
+	if err := validateCredentials(projectID); err != nil {
+		t.Fatalf("Credential validation failed for project %s: %v", projectID, err)
+	}
+
+	for _, run := range runs {
+		run := run
+		t.Run(run.name, func(t *testing.T) {
+			runVPCCase(t, projectID, run)
+		})
+	}
+}
+
+// defaultVPCMTU is the MTU of networks created without an explicit mtu.
+const defaultVPCMTU = 1460
+
+// networkMTU returns the network's MTU, applying the default when unset.
+func (n vpcNetwork) networkMTU() int {
+	if n.MTU == 0 {
+		return defaultVPCMTU
+	}
+	return n.MTU
+}
+
+// checkVPCMTU returns an error if the network's MTU is not expectedMTU.
+func checkVPCMTU(n vpcNetwork, expectedMTU int) error {
+	if actual := n.networkMTU(); actual != expectedMTU {
+		return fmt.Errorf("MTU is %d, want %d", actual, expectedMTU)
+	}
+	return nil
+}
+
+// AssertVPCMTU fails the test unless the network's MTU is expectedMTU, e.g.
+// 8896 for jumbo frames.
+func AssertVPCMTU(t *testing.T, projectID, network string, expectedMTU int) {
+	t.Helper()
+
+	n := describeNetwork(t, projectID, network)
+	t.Logf("Network %s MTU: %d", network, n.networkMTU())
+	if err := checkVPCMTU(n, expectedMTU); err != nil {
+		t.Errorf("Network %s: %v", network, err)
+	}
+}
@@ -0,0 +1,104 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandVPCCases(t *testing.T) {
+	runs, err := expandVPCCases([]VPCTestCase{
+		{Name: "europe regional", CIDR: "10.10.0.0/24", Region: "europe-west1"},
+		{Name: "us_global", CIDR: "10.20.0.0/20", Region: "us-central1", RoutingMode: "global"},
+	}, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []vpcRun{
+		{name: "europe regional", network: "vpc-test-europe-regional-net-abc123", subnet: "vpc-test-europe-regional-sub-abc123",
+			cidr: "10.10.0.0/24", region: "europe-west1", routingMode: "REGIONAL"},
+		{name: "us_global", network: "vpc-test-us-global-net-abc123", subnet: "vpc-test-us-global-sub-abc123",
+			cidr: "10.20.0.0/20", region: "us-central1", routingMode: "GLOBAL"},
+	}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("expandVPCCases() = %+v, want %+v", runs, want)
+	}
+
+	invalid := map[string][]VPCTestCase{
+		"no name":      {{CIDR: "10.0.0.0/24", Region: "r"}},
+		"duplicate":    {{Name: "a", CIDR: "10.0.0.0/24", Region: "r"}, {Name: "a", CIDR: "10.1.0.0/24", Region: "r"}},
+		"bad cidr":     {{Name: "a", CIDR: "10.0.0.0", Region: "r"}},
+		"no region":    {{Name: "a", CIDR: "10.0.0.0/24"}},
+		"routing mode": {{Name: "a", CIDR: "10.0.0.0/24", Region: "r", RoutingMode: "LOCAL"}},
+	}
+	for name, cases := range invalid {
+		if _, err := expandVPCCases(cases, "x"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestVPCResourceNameLength(t *testing.T) {
+	name := vpcResourceName(strings.Repeat("very long case name ", 5), "net", "abc123")
+	if len(name) > 63 || !strings.HasSuffix(name, "-net-abc123") {
+		t.Errorf("vpcResourceName() = %q (%d chars)", name, len(name))
+	}
+}
+
+func TestRunVPCCasesCreatesSubtests(t *testing.T) {
+	t.Setenv(testProjectEnv, "test-project")
+	stubGcloud(t, `{"projectId":"test-project","lifecycleState":"ACTIVE"}`)
+
+	orig := runVPCCase
+	t.Cleanup(func() { runVPCCase = orig })
+	var ran []string
+	runVPCCase = func(t *testing.T, projectID string, run vpcRun) {
+		ran = append(ran, t.Name())
+	}
+
+	RunVPCCases(t, []VPCTestCase{
+		{Name: "regional", CIDR: "10.10.0.0/24", Region: "europe-west1"},
+		{Name: "global", CIDR: "10.20.0.0/24", Region: "us-central1", RoutingMode: "GLOBAL"},
+	})
+
+	want := []string{t.Name() + "/regional", t.Name() + "/global"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("subtests = %v, want %v", ran, want)
+	}
+}
+
+func TestCheckVPCCase(t *testing.T) {
+	run := vpcRun{subnet: "s", cidr: "10.10.0.0/24", routingMode: "GLOBAL"}
+	if got := checkVPCCase(run, "GLOBAL", subnetwork{IPCidrRange: "10.10.0.0/24"}); len(got) != 0 {
+		t.Errorf("checkVPCCase() = %v, want no problems", got)
+	}
+	if got := checkVPCCase(run, "REGIONAL", subnetwork{IPCidrRange: "10.11.0.0/24"}); len(got) != 2 {
+		t.Errorf("checkVPCCase() = %v, want 2 problems", got)
+	}
+}
+
+func TestCheckVPCMTU(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected int
+		wantErr  bool
+	}{
+		{"jumbo frames", `{"name":"vpc","mtu":8896}`, 8896, false},
+		{"default expected", `{"name":"vpc"}`, 1460, false},
+		{"default but jumbo expected", `{"name":"vpc"}`, 8896, true},
+		{"1500", `{"name":"vpc","mtu":1500}`, 8896, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var n vpcNetwork
+			if err := json.Unmarshal([]byte(tc.raw), &n); err != nil {
+				t.Fatal(err)
+			}
+			if err := checkVPCMTU(n, tc.expected); (err != nil) != tc.wantErr {
+				t.Errorf("checkVPCMTU() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}